@@ -9,16 +9,24 @@ type (
 	Config struct {
 		Azure      Azure `yaml:"azure"`
 		Collectors struct {
-			General        CollectorBase           `yaml:"general"`
-			Resource       CollectorBase           `yaml:"resource"`
-			Quota          CollectorBase           `yaml:"quota"`
-			Defender       CollectorBase           `yaml:"defender"`
-			ResourceHealth CollectorResourceHealth `yaml:"resourceHealth"`
-			Iam            CollectorBase           `yaml:"iam"`
-			Graph          CollectorGraph          `yaml:"graph"`
-			Costs          CollectorCosts          `yaml:"costs"`
-			Reservation    CollectorReservation    `yaml:"reservation"`
-			Portscan       CollectorPortscan       `yaml:"portscan"`
+			General          CollectorGeneral          `yaml:"general"`
+			Resource         CollectorResource         `yaml:"resource"`
+			Quota            CollectorQuota            `yaml:"quota"`
+			Defender         CollectorBase             `yaml:"defender"`
+			ResourceHealth   CollectorResourceHealth   `yaml:"resourceHealth"`
+			Iam              CollectorBase             `yaml:"iam"`
+			Graph            CollectorGraph            `yaml:"graph"`
+			Costs            CollectorCosts            `yaml:"costs"`
+			Reservation      CollectorReservation      `yaml:"reservation"`
+			VmSku            CollectorVmSku            `yaml:"vmSku"`
+			Waste            CollectorWaste            `yaml:"waste"`
+			TagCompliance    CollectorTagCompliance    `yaml:"tagCompliance"`
+			Lock             CollectorLock             `yaml:"lock"`
+			ResourceProvider CollectorResourceProvider `yaml:"resourceProvider"`
+			Deployment       CollectorDeployment       `yaml:"deployment"`
+			ActivityLog      CollectorActivityLog      `yaml:"activityLog"`
+			ManagementGroup  CollectorManagementGroup  `yaml:"managementGroup"`
+			Portscan         CollectorPortscan         `yaml:"portscan"`
 		} `yaml:"collectors"`
 	}
 
@@ -40,6 +48,19 @@ func (c *CollectorBase) IsEnabled() bool {
 	return c.ScrapeTime != nil && c.ScrapeTime.Seconds() > 0
 }
 
+// Validate checks config for invalid values which cannot be detected by yaml parsing
+func (c *Config) Validate() error {
+	if err := c.Collectors.Costs.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Collectors.Quota.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (c *Config) GetJson() []byte {
 	jsonBytes, err := json.Marshal(c)
 	if err != nil {
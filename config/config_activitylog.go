@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+type (
+	CollectorActivityLog struct {
+		CollectorBase `yaml:",inline"`
+
+		// event categories counted in azurerm_activitylog_operations_total
+		Categories []string `yaml:"categories"`
+
+		// lookback of first run (without checkpoint)
+		InitialLookback *time.Duration `yaml:"initialLookback"`
+
+		// maximum lookback of catch-up runs (eg. after restore of an old checkpoint)
+		MaxLookback *time.Duration `yaml:"maxLookback"`
+
+		// delay for ingestion latency of Activity Log events
+		Delay *time.Duration `yaml:"delay"`
+	}
+)
+
+func (c *CollectorActivityLog) GetCategories() []string {
+	if len(c.Categories) > 0 {
+		return c.Categories
+	}
+	return []string{"Administrative", "ServiceHealth", "ResourceHealth"}
+}
+
+func (c *CollectorActivityLog) IsCategoryEnabled(category string) bool {
+	for _, val := range c.GetCategories() {
+		if strings.EqualFold(val, category) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CollectorActivityLog) GetInitialLookback() time.Duration {
+	if c.InitialLookback != nil {
+		return *c.InitialLookback
+	}
+	return 1 * time.Hour
+}
+
+func (c *CollectorActivityLog) GetMaxLookback() time.Duration {
+	maxLookback := 24 * time.Hour
+	if c.MaxLookback != nil {
+		maxLookback = *c.MaxLookback
+	}
+
+	if lookback := c.GetInitialLookback(); maxLookback < lookback {
+		maxLookback = lookback
+	}
+
+	// Activity Log events are only available for 90 days
+	if retention := 90 * 24 * time.Hour; maxLookback > retention {
+		maxLookback = retention
+	}
+
+	return maxLookback
+}
+
+func (c *CollectorActivityLog) GetDelay() time.Duration {
+	if c.Delay != nil {
+		return *c.Delay
+	}
+	return 5 * time.Minute
+}
@@ -12,24 +12,45 @@ type (
 
 		RequestDelay time.Duration `yaml:"requestDelay"`
 
-		Queries []CollectorCostsQuery `yaml:"queries"`
+		Queries []CollectorCostsQuery  `yaml:"queries"`
+		Exports []CollectorCostsExport `yaml:"exports"`
 	}
 
 	CollectorCostsQuery struct {
-		Name          string                         `yaml:"name"`
-		Help          *string                        `yaml:"help"`
-		Scopes        *[]string                      `yaml:"scopes"`
-		Subscriptions *[]string                      `yaml:"subscriptions"`
-		TimeFrames    []string                       `yaml:"timeFrames"`
-		Dimensions    []string                       `yaml:"dimensions"`
-		ExportType    string                         `yaml:"exportType"`
-		Granularity   string                         `yaml:"granularity"`
-		ValueField    string                         `yaml:"valueField"`
-		Labels        map[string]string              `yaml:"labels"`
-		TimePeriod    *CollectorCostsQueryTimePeriod `yaml:"timePeriod"`
+		Name           string                             `yaml:"name"`
+		Help           *string                            `yaml:"help"`
+		Scopes         *[]string                          `yaml:"scopes"`
+		ScopeTemplates *CollectorCostsQueryScopeTemplates `yaml:"scopeTemplates"`
+		Subscriptions  *[]string                          `yaml:"subscriptions"`
+		TimeFrames     []string                           `yaml:"timeFrames"`
+		Dimensions     []string                           `yaml:"dimensions"`
+		ExportType     string                             `yaml:"exportType"`
+		Granularity    string                             `yaml:"granularity"`
+		ValueField     string                             `yaml:"valueField"`
+		Labels         map[string]string                  `yaml:"labels"`
+		TimePeriod     *CollectorCostsQueryTimePeriod     `yaml:"timePeriod"`
+		Trend          *CollectorCostsQueryTrend          `yaml:"trend"`
 
 		config *configCollectorCostsQueryConfig
 	}
+	CollectorCostsQueryScopeTemplates struct {
+		// "all" (or "discover") for all visible management groups
+		// or a management group ID to use this group and all its descendant groups
+		ManagementGroups *string `yaml:"managementGroups"`
+
+		// "all" (or "discover") for all visible billing profiles
+		// or a billing account ID to use all billing profiles of this account
+		BillingProfiles *string `yaml:"billingProfiles"`
+	}
+
+	CollectorCostsQueryTrend struct {
+		// days used for average and standard deviation of anomaly score
+		Window int `yaml:"window"`
+
+		// z-score threshold for anomaly flag
+		Threshold float64 `yaml:"threshold"`
+	}
+
 	CollectorCostsQueryTimePeriod struct {
 		From         *time.Time     `yaml:"from"`
 		FromDuration *time.Duration `yaml:"fromDuration"`
@@ -48,6 +69,31 @@ type (
 	}
 )
 
+// Validate checks for duplicate query and export names (queries and exports share the metric namespace)
+func (c *CollectorCosts) Validate() error {
+	metricNames := map[string]string{}
+
+	for _, query := range c.Queries {
+		if err := query.ValidateTrend(); err != nil {
+			return err
+		}
+
+		if val, exists := metricNames[query.GetMetricName()]; exists {
+			return fmt.Errorf(`costs query "%v" uses same metric name "%v" as %v`, query.Name, query.GetMetricName(), val)
+		}
+		metricNames[query.GetMetricName()] = fmt.Sprintf(`query "%v"`, query.Name)
+	}
+
+	for _, export := range c.Exports {
+		if val, exists := metricNames[export.GetMetricName()]; exists {
+			return fmt.Errorf(`costs export "%v" uses same metric name "%v" as %v`, export.Name, export.GetMetricName(), val)
+		}
+		metricNames[export.GetMetricName()] = fmt.Sprintf(`export "%v"`, export.Name)
+	}
+
+	return nil
+}
+
 func (q *CollectorCostsQuery) GetMetricName() string {
 	return fmt.Sprintf(`azurerm_costs_%v`, q.Name)
 }
@@ -60,6 +106,73 @@ func (q *CollectorCostsQuery) GetMetricHelp() string {
 	}
 }
 
+// IsExportTypeBoth returns true if the query should run with ActualCost and AmortizedCost
+func (q *CollectorCostsQuery) IsExportTypeBoth() bool {
+	return strings.EqualFold(q.ExportType, "Both")
+}
+
+// HasTrend returns true if trend and anomaly metrics should be calculated (only for daily granularity)
+func (q *CollectorCostsQuery) HasTrend() bool {
+	return q.Trend != nil && q.Granularity == "Daily"
+}
+
+// ValidateTrend checks that trend window (and 7 days for week-over-week delta) fits into the query timeframe
+// the window is anchored on the last complete day, so only Custom timeframes with relative timePeriod are supported
+func (q *CollectorCostsQuery) ValidateTrend() error {
+	if !q.HasTrend() {
+		return nil
+	}
+
+	days := q.GetTrendWindow()
+	if days < 7 {
+		days = 7
+	}
+
+	for _, timeframe := range q.TimeFrames {
+		if timeframe != "Custom" {
+			return fmt.Errorf(`costs query "%v" with trend only supports timeFrame "Custom", found "%v"`, q.Name, timeframe)
+		}
+	}
+
+	if q.TimePeriod == nil || q.TimePeriod.From != nil || q.TimePeriod.To != nil || q.TimePeriod.FromDuration == nil {
+		return fmt.Errorf(`costs query "%v" with trend needs timePeriod with fromDuration (and optional toDuration)`, q.Name)
+	}
+
+	// current (incomplete) day is not used, so one additional day is needed
+	if minFromDuration := -time.Duration(days+1) * 24 * time.Hour; *q.TimePeriod.FromDuration > minFromDuration {
+		return fmt.Errorf(`costs query "%v" with trend needs timePeriod.fromDuration of at least %v (trend window of %v days)`, q.Name, minFromDuration, days)
+	}
+
+	if q.TimePeriod.ToDuration != nil && *q.TimePeriod.ToDuration < 0 {
+		return fmt.Errorf(`costs query "%v" with trend needs timePeriod.toDuration of at least 0s (latest complete day)`, q.Name)
+	}
+
+	return nil
+}
+
+func (q *CollectorCostsQuery) GetTrendWindow() int {
+	if q.Trend != nil && q.Trend.Window > 1 {
+		return q.Trend.Window
+	}
+	return 14
+}
+
+func (q *CollectorCostsQuery) GetTrendThreshold() float64 {
+	if q.Trend != nil && q.Trend.Threshold > 0 {
+		return q.Trend.Threshold
+	}
+	return 3
+}
+
+func (q *CollectorCostsQuery) HasScopeTemplates() bool {
+	return q.ScopeTemplates != nil && (q.ScopeTemplates.ManagementGroups != nil || q.ScopeTemplates.BillingProfiles != nil)
+}
+
+// IsScopeTemplateDiscoverAll returns true if the scope template should discover all visible scopes
+func IsScopeTemplateDiscoverAll(val string) bool {
+	return strings.EqualFold(val, "all") || strings.EqualFold(val, "discover")
+}
+
 func (q *CollectorCostsQuery) GetConfig() *configCollectorCostsQueryConfig {
 	if q.config == nil {
 		q.config = &configCollectorCostsQueryConfig{
@@ -67,20 +180,11 @@ func (q *CollectorCostsQuery) GetConfig() *configCollectorCostsQueryConfig {
 		}
 
 		for _, dimension := range q.Dimensions {
-			labelName := lowerFirst(prometheusLabelReplacerRegExp.ReplaceAllString(dimension, "_"))
-
-			switch {
-			case strings.EqualFold(dimension, "ResourceGroupName"):
-				labelName = "resourceGroup"
-			case strings.EqualFold(dimension, "ResourceId"):
-				labelName = "resourceID"
-			}
-
 			q.config.Dimensions = append(
 				q.config.Dimensions,
 				configCollectorCostsQueryConfigDimension{
 					Dimension: dimension,
-					Label:     labelName,
+					Label:     costDimensionLabelName(dimension),
 				},
 			)
 
@@ -90,3 +194,19 @@ func (q *CollectorCostsQuery) GetConfig() *configCollectorCostsQueryConfig {
 
 	return q.config
 }
+
+// costDimensionLabelName returns the prometheus label name for a cost dimension
+func costDimensionLabelName(dimension string) string {
+	labelName := lowerFirst(prometheusLabelReplacerRegExp.ReplaceAllString(dimension, "_"))
+
+	switch {
+	case strings.EqualFold(dimension, "ResourceGroupName"),
+		strings.EqualFold(dimension, "ResourceGroup"),
+		strings.EqualFold(dimension, "x_ResourceGroupName"):
+		labelName = "resourceGroup"
+	case strings.EqualFold(dimension, "ResourceId"):
+		labelName = "resourceID"
+	}
+
+	return labelName
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	CostsExportFormatUsage = "usage"
+	CostsExportFormatFocus = "focus"
+)
+
+type (
+	CollectorCostsExport struct {
+		Name        string                         `yaml:"name"`
+		Help        *string                        `yaml:"help"`
+		Path        string                         `yaml:"path"`
+		Format      string                         `yaml:"format"`
+		AllRuns     bool                           `yaml:"allRuns"`
+		Dimensions  []string                       `yaml:"dimensions"`
+		Granularity string                         `yaml:"granularity"`
+		ValueField  string                         `yaml:"valueField"`
+		Labels      map[string]string              `yaml:"labels"`
+		TimePeriod  *CollectorCostsQueryTimePeriod `yaml:"timePeriod"`
+
+		config *configCollectorCostsQueryConfig
+	}
+)
+
+func (e *CollectorCostsExport) GetMetricName() string {
+	return fmt.Sprintf(`azurerm_costs_%v`, e.Name)
+}
+
+func (e *CollectorCostsExport) GetMetricHelp() string {
+	if e.Help != nil {
+		return *e.Help
+	} else {
+		return fmt.Sprintf(`Azure ResourceManager costmanagement export with dimensions %v`, strings.Join(e.Dimensions, ", "))
+	}
+}
+
+// GetFormat returns the export format (usage or focus)
+func (e *CollectorCostsExport) GetFormat() string {
+	if strings.EqualFold(e.Format, CostsExportFormatFocus) {
+		return CostsExportFormatFocus
+	}
+	return CostsExportFormatUsage
+}
+
+// GetValueField returns the configured value column or the default cost column of the export format
+func (e *CollectorCostsExport) GetValueField() string {
+	if e.ValueField != "" {
+		return e.ValueField
+	}
+
+	switch e.GetFormat() {
+	case CostsExportFormatFocus:
+		return "BilledCost"
+	default:
+		return "CostInBillingCurrency"
+	}
+}
+
+func (e *CollectorCostsExport) GetConfig() *configCollectorCostsQueryConfig {
+	if e.config == nil {
+		e.config = &configCollectorCostsQueryConfig{
+			Dimensions: []configCollectorCostsQueryConfigDimension{},
+		}
+
+		for _, dimension := range e.Dimensions {
+			e.config.Dimensions = append(
+				e.config.Dimensions,
+				configCollectorCostsQueryConfigDimension{
+					Dimension: dimension,
+					Label:     costDimensionLabelName(dimension),
+				},
+			)
+		}
+	}
+
+	return e.config
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCollectorCostsQueryValidateTrend(t *testing.T) {
+	duration := func(val string) *time.Duration {
+		ret, err := time.ParseDuration(val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &ret
+	}
+	now := time.Now()
+
+	testCases := []struct {
+		name       string
+		timeFrames []string
+		timePeriod *CollectorCostsQueryTimePeriod
+		window     int
+		valid      bool
+	}{
+		{name: "default window", timeFrames: []string{"Custom"}, timePeriod: &CollectorCostsQueryTimePeriod{FromDuration: duration("-360h")}, valid: true},
+		{name: "default window too short", timeFrames: []string{"Custom"}, timePeriod: &CollectorCostsQueryTimePeriod{FromDuration: duration("-336h")}, valid: false},
+		{name: "small window needs 7 days", timeFrames: []string{"Custom"}, timePeriod: &CollectorCostsQueryTimePeriod{FromDuration: duration("-96h")}, window: 3, valid: false},
+		{name: "small window", timeFrames: []string{"Custom"}, timePeriod: &CollectorCostsQueryTimePeriod{FromDuration: duration("-192h"), ToDuration: duration("0s")}, window: 3, valid: true},
+		{name: "toDuration in past", timeFrames: []string{"Custom"}, timePeriod: &CollectorCostsQueryTimePeriod{FromDuration: duration("-720h"), ToDuration: duration("-48h")}, valid: false},
+		{name: "absolute timePeriod", timeFrames: []string{"Custom"}, timePeriod: &CollectorCostsQueryTimePeriod{From: &now, FromDuration: duration("-720h")}, valid: false},
+		{name: "missing timePeriod", timeFrames: []string{"Custom"}, valid: false},
+		{name: "month to date", timeFrames: []string{"MonthToDate"}, timePeriod: &CollectorCostsQueryTimePeriod{FromDuration: duration("-720h")}, valid: false},
+	}
+
+	for _, testCase := range testCases {
+		query := CollectorCostsQuery{
+			Name:        "test",
+			Granularity: "Daily",
+			TimeFrames:  testCase.timeFrames,
+			TimePeriod:  testCase.timePeriod,
+			Trend:       &CollectorCostsQueryTrend{Window: testCase.window},
+		}
+
+		if err := query.ValidateTrend(); (err == nil) != testCase.valid {
+			t.Errorf("%v: expected valid=%v, got error %v", testCase.name, testCase.valid, err)
+		}
+	}
+
+	// trend is ignored without daily granularity
+	query := CollectorCostsQuery{Name: "test", Granularity: "None", TimeFrames: []string{"MonthToDate"}, Trend: &CollectorCostsQueryTrend{}}
+	if err := query.ValidateTrend(); err != nil {
+		t.Errorf("expected no error without daily granularity, got %v", err)
+	}
+}
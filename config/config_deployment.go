@@ -0,0 +1,24 @@
+package config
+
+import (
+	"time"
+)
+
+type (
+	CollectorDeployment struct {
+		CollectorBase `yaml:",inline"`
+
+		// management group IDs for management group scope deployments ("all" for all groups of the hierarchy)
+		ManagementGroups []string `yaml:"managementGroups"`
+
+		// only export deployments (azurerm_deployment_info) newer than this duration, counts include all deployments
+		MaxAge *time.Duration `yaml:"maxAge"`
+	}
+)
+
+func (c *CollectorDeployment) GetMaxAge() time.Duration {
+	if c.MaxAge != nil {
+		return *c.MaxAge
+	}
+	return 7 * 24 * time.Hour
+}
@@ -0,0 +1,21 @@
+package config
+
+type (
+	CollectorGeneral struct {
+		CollectorBase `yaml:",inline"`
+
+		ManagementGroupPath struct {
+			Enabled bool `yaml:"enabled"`
+
+			// number of managementGroupLevelN labels below the root management group
+			Levels *int `yaml:"levels"`
+		} `yaml:"managementGroupPath"`
+	}
+)
+
+func (c *CollectorGeneral) GetManagementGroupLevels() int {
+	if c.ManagementGroupPath.Levels != nil {
+		return *c.ManagementGroupPath.Levels
+	}
+	return 3
+}
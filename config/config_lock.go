@@ -0,0 +1,46 @@
+package config
+
+import (
+	"strings"
+)
+
+type (
+	CollectorLock struct {
+		CollectorBase `yaml:",inline"`
+
+		RequiredLock *CollectorLockRequired `yaml:"requiredLock"`
+	}
+
+	CollectorLockRequired struct {
+		// resourcegroups with all of these tags require a lock (empty tag value: tag only needs to exist)
+		ResourceGroupTags map[string]string `yaml:"resourceGroupTags"`
+
+		// required lock level (CanNotDelete or ReadOnly)
+		Level *string `yaml:"level"`
+	}
+)
+
+func (c *CollectorLockRequired) GetLevel() string {
+	if c.Level != nil {
+		return *c.Level
+	}
+	return "CanNotDelete"
+}
+
+// MatchesTags checks if resourcegroup tags match the tag selector
+func (c *CollectorLockRequired) MatchesTags(tags map[string]string) bool {
+	for selectorName, selectorValue := range c.ResourceGroupTags {
+		found := false
+		for tagName, tagValue := range tags {
+			if strings.EqualFold(tagName, selectorName) {
+				found = selectorValue == "" || strings.EqualFold(strings.TrimSpace(tagValue), selectorValue)
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestCollectorLockRequiredMatchesTags(t *testing.T) {
+	lock := CollectorLockRequired{
+		ResourceGroupTags: map[string]string{
+			"environment": "Prod",
+			"critical":    "",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		tags     map[string]string
+		expected bool
+	}{
+		{name: "all tags match", tags: map[string]string{"environment": "prod", "critical": "yes"}, expected: true},
+		{name: "tag names are case-insensitive", tags: map[string]string{"Environment": " PROD ", "CRITICAL": ""}, expected: true},
+		{name: "value mismatch", tags: map[string]string{"environment": "dev", "critical": "yes"}, expected: false},
+		{name: "tag missing", tags: map[string]string{"environment": "prod"}, expected: false},
+		{name: "no tags", tags: map[string]string{}, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if matches := lock.MatchesTags(test.tags); matches != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, matches)
+			}
+		})
+	}
+
+	if !(&CollectorLockRequired{}).MatchesTags(map[string]string{}) {
+		t.Error("empty selector should match all resourcegroups")
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+type (
+	CollectorManagementGroup struct {
+		CollectorBase `yaml:",inline"`
+
+		// root management group ID of the exported hierarchy and of general managementGroupPath labels (default: tenant root group)
+		Root *string `yaml:"root"`
+	}
+)
+
+func (c *CollectorManagementGroup) GetRoot(tenantId string) string {
+	if c.Root != nil && *c.Root != "" {
+		return *c.Root
+	}
+	return tenantId
+}
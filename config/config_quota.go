@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	QuotaLocationDiscoveryResources    = "resources"
+	QuotaLocationDiscoverySubscription = "subscription"
+)
+
+type (
+	CollectorQuota struct {
+		CollectorBase `yaml:",inline"`
+
+		// discovery of quota locations per subscription (in addition to azure.locations)
+		// "resources": locations of existing resources
+		// "subscription": all (physical) locations available for the subscription
+		LocationDiscovery string `yaml:"locationDiscovery"`
+
+		// resource providers fetched using generic Microsoft.Quota API
+		Providers []CollectorQuotaProvider `yaml:"providers"`
+
+		// collect quota increase requests of providers (Microsoft.Quota/quotaRequests)
+		Requests bool `yaml:"requests"`
+
+		// max age of finished (succeeded, failed, ...) quota increase requests (default: 168h)
+		RequestsMaxAge *time.Duration `yaml:"requestsMaxAge"`
+
+		Headroom *CollectorQuotaHeadroom `yaml:"headroom"`
+
+		Forecast *CollectorQuotaForecast `yaml:"forecast"`
+	}
+
+	CollectorQuotaForecast struct {
+		// history window used for trend calculation (default: 168h)
+		History *time.Duration `yaml:"history"`
+
+		// only export forecasts within horizon (default: 2160h)
+		Horizon *time.Duration `yaml:"horizon"`
+
+		// linear (default) or exponential
+		Model string `yaml:"model"`
+
+		// minimal number of samples for trend calculation (default: 3)
+		MinSamples int `yaml:"minSamples"`
+
+		// minimal interval between two history samples (default: 1h)
+		SampleInterval *time.Duration `yaml:"sampleInterval"`
+
+		// maximum number of history samples per quota (default: history / sampleInterval + 1)
+		MaxSamples int `yaml:"maxSamples"`
+	}
+
+	CollectorQuotaHeadroom struct {
+		// VM sizes (eg. Standard_D4s_v5) for headroom calculation
+		VmSizes []string `yaml:"vmSizes"`
+
+		// refresh interval of cached VM size vCPUs and restrictions (default: 12h)
+		SkuRefresh *time.Duration `yaml:"skuRefresh"`
+	}
+
+	CollectorQuotaProvider struct {
+		// resource provider, eg. Microsoft.Sql
+		Provider string `yaml:"provider"`
+
+		// value of scope label (default: provider without "Microsoft." prefix, lowercase)
+		Name *string `yaml:"name"`
+
+		// locations (default: azure.locations and discovered locations)
+		Locations []string `yaml:"locations"`
+
+		// quota scope template (default: /subscriptions/{subscriptionId}/providers/{provider}/locations/{location})
+		Scope *string `yaml:"scope"`
+	}
+)
+
+func (p *CollectorQuotaProvider) GetName() string {
+	if p.Name != nil {
+		return *p.Name
+	}
+
+	name := strings.ToLower(p.Provider)
+	name = strings.TrimPrefix(name, "microsoft.")
+	return name
+}
+
+func (p *CollectorQuotaProvider) GetScopeTemplate() string {
+	if p.Scope != nil {
+		return *p.Scope
+	}
+	return "/subscriptions/{subscriptionId}/providers/{provider}/locations/{location}"
+}
+
+// Validate checks for unknown location discovery modes
+func (c *CollectorQuota) Validate() error {
+	switch strings.ToLower(c.LocationDiscovery) {
+	case "", QuotaLocationDiscoveryResources, QuotaLocationDiscoverySubscription:
+		return nil
+	default:
+		return fmt.Errorf(`quota locationDiscovery "%v" is not supported (supported: %v, %v)`, c.LocationDiscovery, QuotaLocationDiscoveryResources, QuotaLocationDiscoverySubscription)
+	}
+}
+
+// GetLocationDiscovery returns location discovery mode (lowercase, empty if disabled)
+func (c *CollectorQuota) GetLocationDiscovery() string {
+	return strings.ToLower(c.LocationDiscovery)
+}
+
+// HasLocationDiscovery returns true if quota locations should be discovered per subscription
+func (c *CollectorQuota) HasLocationDiscovery() bool {
+	return c.GetLocationDiscovery() != ""
+}
+
+// HasProvider returns true if the resource provider is fetched using generic Microsoft.Quota API
+func (c *CollectorQuota) HasProvider(provider string) bool {
+	for _, row := range c.Providers {
+		if strings.EqualFold(row.Provider, provider) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CollectorQuota) GetRequestsMaxAge() time.Duration {
+	if c.RequestsMaxAge != nil && c.RequestsMaxAge.Seconds() > 0 {
+		return *c.RequestsMaxAge
+	}
+	return 7 * 24 * time.Hour
+}
+
+func (h *CollectorQuotaHeadroom) GetSkuRefresh() time.Duration {
+	if h.SkuRefresh != nil && h.SkuRefresh.Seconds() > 0 {
+		return *h.SkuRefresh
+	}
+	return 12 * time.Hour
+}
+
+func (f *CollectorQuotaForecast) GetHistory() time.Duration {
+	if f.History != nil && f.History.Seconds() > 0 {
+		return *f.History
+	}
+	return 7 * 24 * time.Hour
+}
+
+func (f *CollectorQuotaForecast) GetHorizon() time.Duration {
+	if f.Horizon != nil && f.Horizon.Seconds() > 0 {
+		return *f.Horizon
+	}
+	return 90 * 24 * time.Hour
+}
+
+func (f *CollectorQuotaForecast) IsExponential() bool {
+	return strings.EqualFold(f.Model, "exponential")
+}
+
+func (f *CollectorQuotaForecast) GetMinSamples() int {
+	if f.MinSamples >= 2 {
+		return f.MinSamples
+	}
+	return 3
+}
+
+func (f *CollectorQuotaForecast) GetSampleInterval() time.Duration {
+	if f.SampleInterval != nil && f.SampleInterval.Seconds() > 0 {
+		return *f.SampleInterval
+	}
+	return 1 * time.Hour
+}
+
+func (f *CollectorQuotaForecast) GetMaxSamples() int {
+	if f.MaxSamples >= f.GetMinSamples() {
+		return f.MaxSamples
+	}
+	return int(f.GetHistory()/f.GetSampleInterval()) + 1
+}
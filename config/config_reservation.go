@@ -7,5 +7,75 @@ type (
 		Scopes      []string `yaml:"scopes"`
 		Granularity string   `yaml:"granularity"`
 		FromDays    int      `yaml:"fromDays"`
+
+		// only export latest complete period (without usageDate label) and min/avg/max over fromDays
+		LatestOnly bool `yaml:"latestOnly"`
+
+		// collect reservation usage per consuming resource
+		Details bool `yaml:"details"`
+
+		// collect reservation orders and reservations (inventory incl. expiry)
+		Inventory bool `yaml:"inventory"`
+
+		Recommendations *CollectorReservationRecommendations `yaml:"recommendations"`
+
+		SavingsPlans *CollectorReservationSavingsPlans `yaml:"savingsPlans"`
+	}
+
+	CollectorReservationRecommendations struct {
+		// Single and/or Shared
+		ScopeTypes []string `yaml:"scopeTypes"`
+
+		// Last7Days, Last30Days and/or Last60Days
+		LookBackPeriods []string `yaml:"lookBackPeriods"`
+
+		// P1Y and/or P3Y
+		Terms []string `yaml:"terms"`
+
+		// VirtualMachines, SQLDatabases, PostgreSQL, ManagedDisk, MySQL, RedHat, MariaDB, RedisCache, CosmosDB, ...
+		ResourceTypes []string `yaml:"resourceTypes"`
+	}
+
+	CollectorReservationSavingsPlans struct {
+		// Hourly, Daily or Monthly (default: granularity of reservation collector)
+		Granularity string `yaml:"granularity"`
 	}
 )
+
+func (r *CollectorReservationRecommendations) GetScopeTypes() []string {
+	if len(r.ScopeTypes) > 0 {
+		return r.ScopeTypes
+	}
+	return []string{"Shared"}
+}
+
+func (r *CollectorReservationRecommendations) GetLookBackPeriods() []string {
+	if len(r.LookBackPeriods) > 0 {
+		return r.LookBackPeriods
+	}
+	return []string{"Last30Days"}
+}
+
+func (r *CollectorReservationRecommendations) GetTerms() []string {
+	if len(r.Terms) > 0 {
+		return r.Terms
+	}
+	return []string{"P1Y", "P3Y"}
+}
+
+func (r *CollectorReservationRecommendations) GetResourceTypes() []string {
+	if len(r.ResourceTypes) > 0 {
+		return r.ResourceTypes
+	}
+	return []string{"VirtualMachines"}
+}
+
+func (r *CollectorReservation) GetSavingsPlanGranularity() string {
+	if r.SavingsPlans != nil && r.SavingsPlans.Granularity != "" {
+		return r.SavingsPlans.Granularity
+	}
+	if r.Granularity != "" {
+		return r.Granularity
+	}
+	return "daily"
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"strings"
+)
+
+const (
+	ResourceBackendArm           = "arm"
+	ResourceBackendResourceGraph = "resourcegraph"
+)
+
+type (
+	CollectorResource struct {
+		CollectorBase `yaml:",inline"`
+
+		// backend used for listing resources and resourcegroups (arm or resourcegraph)
+		Backend string `yaml:"backend"`
+
+		ResourceGraph CollectorResourceGraph `yaml:"resourceGraph"`
+
+		Info  CollectorResourceInfo  `yaml:"info"`
+		Count CollectorResourceCount `yaml:"count"`
+
+		Details CollectorResourceDetails `yaml:"details"`
+
+		Lifecycle CollectorResourceLifecycle `yaml:"lifecycle"`
+	}
+
+	CollectorResourceGraph struct {
+		// number of subscriptions per Resource Graph query
+		BatchSize int `yaml:"batchSize"`
+	}
+
+	CollectorResourceInfo struct {
+		// export azurerm_resource_info (default: true)
+		Enabled *bool `yaml:"enabled"`
+
+		// ratio (0-1) of resources exported as azurerm_resource_info, sampled by resourceID
+		SampleRatio *float64 `yaml:"sampleRatio"`
+	}
+
+	CollectorResourceCount struct {
+		// export azurerm_resource_count
+		Enabled bool `yaml:"enabled"`
+
+		// tags added as labels to azurerm_resource_count (same syntax as azure.resourceTags)
+		Tags []string `yaml:"tags"`
+	}
+
+	CollectorResourceDetails struct {
+		// export sku, kind, managedBy, zones and identity metrics
+		Enabled bool `yaml:"enabled"`
+
+		// export createdTime and changedTime (arm backend only)
+		Timestamps bool `yaml:"timestamps"`
+	}
+
+	CollectorResourceLifecycle struct {
+		// track resource inventory between runs (first seen, created and deleted resources)
+		Enabled bool `yaml:"enabled"`
+
+		// path of JSON-lines change log (added, removed and tag changes), empty to disable
+		ChangeLog *string `yaml:"changeLog"`
+	}
+)
+
+func (c *CollectorResource) GetBackend() string {
+	switch strings.ToLower(c.Backend) {
+	case ResourceBackendResourceGraph:
+		return ResourceBackendResourceGraph
+	default:
+		return ResourceBackendArm
+	}
+}
+
+func (c *CollectorResource) IsResourceGraphBackend() bool {
+	return c.GetBackend() == ResourceBackendResourceGraph
+}
+
+func (c *CollectorResourceGraph) GetBatchSize() int {
+	if c.BatchSize > 0 {
+		return c.BatchSize
+	}
+	return 1000
+}
+
+func (c *CollectorResourceInfo) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+func (c *CollectorResourceInfo) GetSampleRatio() float64 {
+	if c.SampleRatio != nil {
+		return *c.SampleRatio
+	}
+	return 1
+}
@@ -0,0 +1,10 @@
+package config
+
+type (
+	CollectorResourceProvider struct {
+		CollectorBase `yaml:",inline"`
+
+		// resource providers which must be registered in every subscription (eg. Microsoft.Insights)
+		Required []string `yaml:"required"`
+	}
+)
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"strings"
+)
+
+const (
+	TagComplianceCaseLower = "lower"
+	TagComplianceCaseUpper = "upper"
+)
+
+type (
+	CollectorTagCompliance struct {
+		CollectorBase `yaml:",inline"`
+
+		// tags used as labels for azurerm_tag_compliance_summary (same syntax as azure.resourceTags)
+		SummaryTags []string `yaml:"summaryTags"`
+
+		Rules []CollectorTagComplianceRule `yaml:"rules"`
+	}
+
+	CollectorTagComplianceRule struct {
+		Name *string `yaml:"name"`
+
+		// tag name (case-insensitive)
+		Tag string `yaml:"tag"`
+
+		// resource types (eg. Microsoft.Compute/virtualMachines or Microsoft.Resources/resourceGroups), empty for all
+		ResourceTypes []string `yaml:"resourceTypes"`
+
+		// resourceID prefixes (eg. /subscriptions/xxx or /subscriptions/xxx/resourceGroups/yyy), empty for all
+		Scopes []string `yaml:"scopes"`
+
+		// tag must be set
+		Required bool `yaml:"required"`
+
+		// tag value must be one of these values (case-insensitive)
+		AllowedValues []string `yaml:"allowedValues"`
+
+		// tag value must match regex
+		Regex *string `yaml:"regex"`
+
+		// tag value case (lower or upper)
+		Case *string `yaml:"case"`
+
+		// accept tag value from resourcegroup or subscription if not set on resource
+		Inherit bool `yaml:"inherit"`
+	}
+)
+
+func (c *CollectorTagComplianceRule) GetName() string {
+	if c.Name != nil {
+		return *c.Name
+	}
+	return c.Tag
+}
+
+func (c *CollectorTagComplianceRule) MatchesResourceType(resourceType string) bool {
+	if len(c.ResourceTypes) == 0 {
+		return true
+	}
+
+	for _, val := range c.ResourceTypes {
+		if strings.EqualFold(val, resourceType) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CollectorTagComplianceRule) MatchesScope(resourceId string) bool {
+	if len(c.Scopes) == 0 {
+		return true
+	}
+
+	resourceId = strings.ToLower(resourceId)
+	for _, val := range c.Scopes {
+		scope := strings.TrimSuffix(strings.ToLower(val), "/")
+		if resourceId == scope || strings.HasPrefix(resourceId, scope+"/") {
+			return true
+		}
+	}
+	return false
+}
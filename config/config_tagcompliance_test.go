@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestCollectorTagComplianceRuleMatchesScope(t *testing.T) {
+	rule := CollectorTagComplianceRule{
+		Scopes: []string{
+			"/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/RG-App/",
+			"/subscriptions/00000000-0000-0000-0000-000000000002",
+		},
+	}
+
+	tests := map[string]bool{
+		"/subscriptions/00000000-0000-0000-0000-000000000001/resourcegroups/rg-app":                                   true,
+		"/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg-app/providers/Microsoft.Web/sites/app": true,
+		"/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg-app-other":                             false,
+		"/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg-other":                                 false,
+		"/subscriptions/00000000-0000-0000-0000-000000000002/resourceGroups/rg-other":                                 true,
+		"/subscriptions/00000000-0000-0000-0000-0000000000020/resourceGroups/rg-other":                                false,
+	}
+
+	for resourceId, expected := range tests {
+		if matches := rule.MatchesScope(resourceId); matches != expected {
+			t.Errorf("%v: expected %v, got %v", resourceId, expected, matches)
+		}
+	}
+
+	if !(&CollectorTagComplianceRule{}).MatchesScope("/subscriptions/any") {
+		t.Error("rule without scopes should match all resources")
+	}
+}
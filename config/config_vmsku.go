@@ -0,0 +1,20 @@
+package config
+
+type (
+	CollectorVmSku struct {
+		CollectorBase `yaml:",inline"`
+
+		// VM sizes (eg. Standard_D4s_v5), empty for restricted VM sizes only
+		VmSizes []string `yaml:"vmSizes"`
+
+		// numeric capabilities exported as azurerm_vmsku_capability
+		Capabilities []string `yaml:"capabilities"`
+	}
+)
+
+func (c *CollectorVmSku) GetCapabilities() []string {
+	if len(c.Capabilities) > 0 {
+		return c.Capabilities
+	}
+	return []string{"vCPUs", "vCPUsAvailable", "MemoryGB", "MaxDataDiskCount", "MaxNetworkInterfaces", "GPUs"}
+}
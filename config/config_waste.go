@@ -0,0 +1,47 @@
+package config
+
+import (
+	"strings"
+)
+
+const (
+	WasteReasonUnattachedDisk             = "unattachedDisk"
+	WasteReasonUnattachedNetworkInterface = "unattachedNetworkInterface"
+	WasteReasonUnassociatedPublicIp       = "unassociatedPublicIp"
+	WasteReasonEmptyResourceGroup         = "emptyResourceGroup"
+	WasteReasonEmptyAppServicePlan        = "emptyAppServicePlan"
+	WasteReasonEmptyLoadBalancer          = "emptyLoadBalancer"
+	WasteReasonOldSnapshot                = "oldSnapshot"
+)
+
+type (
+	CollectorWaste struct {
+		CollectorBase `yaml:",inline"`
+
+		// enabled checks (empty for all)
+		Reasons []string `yaml:"reasons"`
+
+		// snapshots older than this number of days are reported
+		SnapshotMaxAgeDays int `yaml:"snapshotMaxAgeDays"`
+	}
+)
+
+func (c *CollectorWaste) IsReasonEnabled(reason string) bool {
+	if len(c.Reasons) == 0 {
+		return true
+	}
+
+	for _, val := range c.Reasons {
+		if strings.EqualFold(val, reason) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CollectorWaste) GetSnapshotMaxAgeDays() int {
+	if c.SnapshotMaxAgeDays > 0 {
+		return c.SnapshotMaxAgeDays
+	}
+	return 90
+}
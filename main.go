@@ -122,6 +122,10 @@ func initConfig() {
 	if err != nil {
 		logger.Fatal(err.Error())
 	}
+
+	if err := Config.Validate(); err != nil {
+		logger.Fatal(err.Error())
+	}
 }
 
 func initAzureConnection() {
@@ -336,6 +340,126 @@ func initMetricCollector() {
 		logger.With(zap.String("collector", collectorName)).Infof("collector disabled")
 	}
 
+	collectorName = "vmSku"
+	if Config.Collectors.VmSku.IsEnabled() {
+		c := collector.New(collectorName, &MetricsCollectorAzureRmVmSku{}, logger)
+		c.SetScapeTime(*Config.Collectors.VmSku.ScrapeTime)
+		c.SetCache(
+			Opts.GetCachePath(collectorName+".json"),
+			collector.BuildCacheTag(cacheTag, Config.Azure, Config.Collectors.VmSku),
+		)
+		if err := c.Start(); err != nil {
+			logger.Fatal(err.Error())
+		}
+	} else {
+		logger.With(zap.String("collector", collectorName)).Infof("collector disabled")
+	}
+
+	collectorName = "waste"
+	if Config.Collectors.Waste.IsEnabled() {
+		c := collector.New(collectorName, &MetricsCollectorAzureRmWaste{}, logger)
+		c.SetScapeTime(*Config.Collectors.Waste.ScrapeTime)
+		c.SetCache(
+			Opts.GetCachePath(collectorName+".json"),
+			collector.BuildCacheTag(cacheTag, Config.Azure, Config.Collectors.Waste),
+		)
+		if err := c.Start(); err != nil {
+			logger.Fatal(err.Error())
+		}
+	} else {
+		logger.With(zap.String("collector", collectorName)).Infof("collector disabled")
+	}
+
+	collectorName = "tagCompliance"
+	if Config.Collectors.TagCompliance.IsEnabled() {
+		c := collector.New(collectorName, &MetricsCollectorAzureRmTagCompliance{}, logger)
+		c.SetScapeTime(*Config.Collectors.TagCompliance.ScrapeTime)
+		c.SetCache(
+			Opts.GetCachePath(collectorName+".json"),
+			collector.BuildCacheTag(cacheTag, Config.Azure, Config.Collectors.TagCompliance),
+		)
+		if err := c.Start(); err != nil {
+			logger.Fatal(err.Error())
+		}
+	} else {
+		logger.With(zap.String("collector", collectorName)).Infof("collector disabled")
+	}
+
+	collectorName = "lock"
+	if Config.Collectors.Lock.IsEnabled() {
+		c := collector.New(collectorName, &MetricsCollectorAzureRmLock{}, logger)
+		c.SetScapeTime(*Config.Collectors.Lock.ScrapeTime)
+		c.SetCache(
+			Opts.GetCachePath(collectorName+".json"),
+			collector.BuildCacheTag(cacheTag, Config.Azure, Config.Collectors.Lock),
+		)
+		if err := c.Start(); err != nil {
+			logger.Fatal(err.Error())
+		}
+	} else {
+		logger.With(zap.String("collector", collectorName)).Infof("collector disabled")
+	}
+
+	collectorName = "resourceProvider"
+	if Config.Collectors.ResourceProvider.IsEnabled() {
+		c := collector.New(collectorName, &MetricsCollectorAzureRmResourceProvider{}, logger)
+		c.SetScapeTime(*Config.Collectors.ResourceProvider.ScrapeTime)
+		c.SetCache(
+			Opts.GetCachePath(collectorName+".json"),
+			collector.BuildCacheTag(cacheTag, Config.Azure, Config.Collectors.ResourceProvider),
+		)
+		if err := c.Start(); err != nil {
+			logger.Fatal(err.Error())
+		}
+	} else {
+		logger.With(zap.String("collector", collectorName)).Infof("collector disabled")
+	}
+
+	collectorName = "deployment"
+	if Config.Collectors.Deployment.IsEnabled() {
+		c := collector.New(collectorName, &MetricsCollectorAzureRmDeployment{}, logger)
+		c.SetScapeTime(*Config.Collectors.Deployment.ScrapeTime)
+		c.SetCache(
+			Opts.GetCachePath(collectorName+".json"),
+			collector.BuildCacheTag(cacheTag, Config.Azure, Config.Collectors.Deployment),
+		)
+		if err := c.Start(); err != nil {
+			logger.Fatal(err.Error())
+		}
+	} else {
+		logger.With(zap.String("collector", collectorName)).Infof("collector disabled")
+	}
+
+	collectorName = "activityLog"
+	if Config.Collectors.ActivityLog.IsEnabled() {
+		c := collector.New(collectorName, &MetricsCollectorAzureRmActivityLog{}, logger)
+		c.SetScapeTime(*Config.Collectors.ActivityLog.ScrapeTime)
+		c.SetCache(
+			Opts.GetCachePath(collectorName+".json"),
+			collector.BuildCacheTag(cacheTag, Config.Azure, Config.Collectors.ActivityLog),
+		)
+		if err := c.Start(); err != nil {
+			logger.Fatal(err.Error())
+		}
+	} else {
+		logger.With(zap.String("collector", collectorName)).Infof("collector disabled")
+	}
+
+	collectorName = "managementGroup"
+	if Config.Collectors.ManagementGroup.IsEnabled() {
+		c := collector.New(collectorName, &MetricsCollectorAzureRmManagementGroup{}, logger)
+		c.SetScapeTime(*Config.Collectors.ManagementGroup.ScrapeTime)
+		c.SetCache(
+			Opts.GetCachePath(collectorName+".json"),
+			collector.BuildCacheTag(cacheTag, Config.Azure, Config.Collectors.ManagementGroup, Opts.Azure.Tenant),
+		)
+		if err := c.Start(); err != nil {
+			logger.Fatal(err.Error())
+		}
+	} else {
+		logger.With(zap.String("collector", collectorName)).Infof("collector disabled")
+	}
+
 	collectorName = "portscan"
 	if Config.Collectors.Portscan.IsEnabled() {
 		// parse collectors.portscan.scanner.ports
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/prometheus/collector"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+)
+
+const (
+	ActivityLogApiVersion = "2015-04-01"
+
+	ActivityLogCheckpointDataName = "activityLogCheckpoint"
+
+	ActivityLogCallerTypeUser             = "user"
+	ActivityLogCallerTypeServicePrincipal = "servicePrincipal"
+	ActivityLogCallerTypeOther            = "other"
+)
+
+var (
+	activityLogGuidRegExp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+type (
+	// activityLogCheckpointList contains the end of the last processed query timeframe (endTime) per subscription
+	activityLogCheckpointList map[string]time.Time
+
+	activityLogValue struct {
+		Value string `json:"value"`
+	}
+
+	activityLogEvent struct {
+		EventTimestamp time.Time              `json:"eventTimestamp"`
+		Caller         string                 `json:"caller"`
+		ResourceID     string                 `json:"resourceId"`
+		Level          string                 `json:"level"`
+		Category       activityLogValue       `json:"category"`
+		OperationName  activityLogValue       `json:"operationName"`
+		ResourceType   activityLogValue       `json:"resourceType"`
+		Status         activityLogValue       `json:"status"`
+		Properties     map[string]interface{} `json:"properties"`
+	}
+)
+
+// PropertyString returns event property as string (empty if not set or not a string)
+func (e *activityLogEvent) PropertyString(name string) string {
+	if val, ok := e.Properties[name].(string); ok {
+		return val
+	}
+	return ""
+}
+
+type MetricsCollectorAzureRmActivityLog struct {
+	collector.Processor
+
+	prometheus struct {
+		activityLogOperations      *prometheus.CounterVec
+		activityLogFailedOperation *prometheus.GaugeVec
+		activityLogHealthEvent     *prometheus.GaugeVec
+	}
+
+	checkpoint struct {
+		lock sync.Mutex
+		list activityLogCheckpointList
+	}
+}
+
+func (m *MetricsCollectorAzureRmActivityLog) Setup(collector *collector.Collector) {
+	m.Processor.Setup(collector)
+
+	m.prometheus.activityLogOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "azurerm_activitylog_operations_total",
+			Help: "Azure Activity Log operation count",
+		},
+		[]string{
+			"subscriptionID",
+			"category",
+			"operationName",
+			"status",
+			"callerType",
+			"resourceType",
+		},
+	)
+	m.Collector.RegisterMetricList("activityLogOperations", m.prometheus.activityLogOperations, false)
+
+	m.prometheus.activityLogFailedOperation = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_activitylog_failed_operation",
+			Help: "Azure Activity Log failed operations since last run (value: event timestamp)",
+		},
+		[]string{
+			"subscriptionID",
+			"category",
+			"operationName",
+			"resourceID",
+			"resourceType",
+			"caller",
+			"callerType",
+		},
+	)
+	m.Collector.RegisterMetricList("activityLogFailedOperation", m.prometheus.activityLogFailedOperation, true)
+
+	m.prometheus.activityLogHealthEvent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_activitylog_health_event",
+			Help: "Azure Activity Log ServiceHealth and ResourceHealth events since last run (value: event timestamp)",
+		},
+		[]string{
+			"subscriptionID",
+			"category",
+			"operationName",
+			"resourceID",
+			"status",
+			"level",
+			"title",
+		},
+	)
+	m.Collector.RegisterMetricList("activityLogHealthEvent", m.prometheus.activityLogHealthEvent, true)
+}
+
+func (m *MetricsCollectorAzureRmActivityLog) Reset() {}
+
+func (m *MetricsCollectorAzureRmActivityLog) Collect(callback chan<- func()) {
+	m.checkpoint.list = m.activityLogCheckpoints()
+
+	// keep checkpoints of successful subscriptions if other subscriptions fail (panic)
+	defer m.Collector.SetData(ActivityLogCheckpointDataName, m.checkpoint.list)
+
+	err := AzureSubscriptionsIterator.ForEachAsync(m.Logger(), func(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+		m.collectActivityLog(subscription, logger)
+	})
+	if err != nil {
+		m.Logger().Panic(err)
+	}
+}
+
+func (m *MetricsCollectorAzureRmActivityLog) collectActivityLog(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	operationsMetric := m.Collector.GetMetricList("activityLogOperations")
+	failedOperationMetric := m.Collector.GetMetricList("activityLogFailedOperation")
+	healthEventMetric := m.Collector.GetMetricList("activityLogHealthEvent")
+
+	subscriptionId := to.StringLower(subscription.SubscriptionID)
+
+	endTime := time.Now().Add(-Config.Collectors.ActivityLog.GetDelay()).UTC()
+	startTime := endTime.Add(-Config.Collectors.ActivityLog.GetInitialLookback())
+
+	m.checkpoint.lock.Lock()
+	if checkpoint, exists := m.checkpoint.list[subscriptionId]; exists {
+		startTime = checkpoint
+	}
+	m.checkpoint.lock.Unlock()
+
+	// old checkpoints (eg. restored after long downtime) are limited to maxLookback (and retention of Activity Log)
+	if minStartTime := endTime.Add(-Config.Collectors.ActivityLog.GetMaxLookback()); startTime.Before(minStartTime) {
+		startTime = minStartTime
+	}
+
+	if !startTime.Before(endTime) {
+		return
+	}
+
+	query := url.Values{}
+	query.Set("api-version", ActivityLogApiVersion)
+	query.Set("$filter", "eventTimestamp ge '"+startTime.Format(time.RFC3339Nano)+"' and eventTimestamp le '"+endTime.Format(time.RFC3339Nano)+"'")
+	query.Set("$select", "eventTimestamp,caller,resourceId,level,category,operationName,resourceType,status,properties")
+
+	operationCount := newResourceCountList()
+
+	urlPath := "/subscriptions/" + *subscription.SubscriptionID + "/providers/Microsoft.Insights/eventtypes/management/values"
+	events, err := listArmApiResources(m.Context(), urlPath, query)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	for _, row := range events {
+		event := activityLogEvent{}
+		if err := json.Unmarshal(row, &event); err != nil {
+			logger.Warnf(`unable to parse activity log event: %v`, err.Error())
+			continue
+		}
+
+		// filter is inclusive, skip events already processed by previous run
+		if !event.EventTimestamp.After(startTime) {
+			continue
+		}
+
+		category := event.Category.Value
+		callerType := activityLogCallerType(event.Caller)
+		resourceType := strings.ToLower(event.ResourceType.Value)
+
+		if Config.Collectors.ActivityLog.IsCategoryEnabled(category) {
+			operationCount.Inc(prometheus.Labels{
+				"subscriptionID": subscriptionId,
+				"category":       category,
+				"operationName":  event.OperationName.Value,
+				"status":         event.Status.Value,
+				"callerType":     callerType,
+				"resourceType":   resourceType,
+			})
+		}
+
+		switch {
+		case strings.EqualFold(category, "ServiceHealth") || strings.EqualFold(category, "ResourceHealth"):
+			healthEventMetric.AddTime(prometheus.Labels{
+				"subscriptionID": subscriptionId,
+				"category":       category,
+				"operationName":  event.OperationName.Value,
+				"resourceID":     stringToStringLower(event.ResourceID),
+				"status":         event.Status.Value,
+				"level":          event.Level,
+				"title":          event.PropertyString("title"),
+			}, event.EventTimestamp)
+		case strings.EqualFold(event.Status.Value, "Failed"):
+			failedOperationMetric.AddTime(prometheus.Labels{
+				"subscriptionID": subscriptionId,
+				"category":       category,
+				"operationName":  event.OperationName.Value,
+				"resourceID":     stringToStringLower(event.ResourceID),
+				"resourceType":   resourceType,
+				"caller":         event.Caller,
+				"callerType":     callerType,
+			}, event.EventTimestamp)
+		}
+	}
+
+	for _, row := range operationCount.rows {
+		operationsMetric.Add(row.labels, row.count)
+	}
+
+	m.checkpoint.lock.Lock()
+	m.checkpoint.list[subscriptionId] = endTime
+	m.checkpoint.lock.Unlock()
+}
+
+// activityLogCallerType detects caller type from caller (UPN for users, GUID for service principals)
+func activityLogCallerType(caller string) string {
+	switch {
+	case strings.Contains(caller, "@"):
+		return ActivityLogCallerTypeUser
+	case activityLogGuidRegExp.MatchString(caller):
+		return ActivityLogCallerTypeServicePrincipal
+	default:
+		return ActivityLogCallerTypeOther
+	}
+}
+
+// activityLogCheckpoints returns checkpoints from collector data (or from cache file after restart)
+func (m *MetricsCollectorAzureRmActivityLog) activityLogCheckpoints() activityLogCheckpointList {
+	if checkpoints, ok := m.Collector.GetData(ActivityLogCheckpointDataName).(activityLogCheckpointList); ok {
+		return checkpoints
+	}
+
+	checkpoints := activityLogCheckpointList{}
+	if restoreCollectorData(m.Collector, m.Logger(), ActivityLogCheckpointDataName, &checkpoints, Config.Azure, Config.Collectors.ActivityLog) {
+		m.Logger().Infof(`restored activity log checkpoints of %v subscriptions from cache`, len(checkpoints))
+	}
+
+	return checkpoints
+}
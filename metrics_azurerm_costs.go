@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +15,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/consumption/armconsumption"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/costmanagement/armcostmanagement"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/webdevops/go-common/azuresdk/armclient"
@@ -54,6 +57,19 @@ type (
 		Label     string
 	}
 
+	CostQueryScope struct {
+		Scope string
+		Name  string
+	}
+
+	costBillingResource struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Properties struct {
+			DisplayName string `json:"displayName"`
+		} `json:"properties"`
+	}
+
 	CostQueryConfigDimension struct {
 		Name string
 		Type armcostmanagement.QueryColumnType
@@ -136,11 +152,19 @@ func (m *MetricsCollectorAzureRmCosts) Setup(collector *collector.Collector) {
 
 		costLabels := []string{
 			"scope",
+		}
+
+		if query.HasScopeTemplates() {
+			costLabels = append(costLabels, "scopeName")
+		}
+
+		costLabels = append(
+			costLabels,
 			"subscriptionID",
 			"currency",
 			"timeframe",
 			"granularity",
-		}
+		)
 
 		// add dimension labels
 		for _, dimension := range queryConfig.Dimensions {
@@ -161,6 +185,14 @@ func (m *MetricsCollectorAzureRmCosts) Setup(collector *collector.Collector) {
 			costLabels = append(costLabels, labelName)
 		}
 
+		if query.IsExportTypeBoth() {
+			costLabels = append(costLabels, "exportType")
+		}
+
+		if query.HasTrend() {
+			m.setupCostQueryTrend(query, append([]string{}, costLabels...))
+		}
+
 		if query.Granularity == "Daily" || query.Granularity == "Monthly" {
 			costLabels = append(costLabels, "date", "dateISO")
 		}
@@ -178,6 +210,57 @@ func (m *MetricsCollectorAzureRmCosts) Setup(collector *collector.Collector) {
 			true,
 		)
 	}
+
+	// ----------------------------------------------------
+	// Costs (by Export)
+
+	for _, export := range Config.Collectors.Costs.Exports {
+		exportConfig := export.GetConfig()
+
+		costLabels := []string{
+			"scope",
+			"subscriptionID",
+			"currency",
+			"timeframe",
+			"granularity",
+		}
+
+		// add dimension labels
+		for _, dimension := range exportConfig.Dimensions {
+			switch dimension.Label {
+			case "resourceGroup":
+				// add additional resourceGroup labels
+				costLabels = AzureResourceGroupTagManager.AddToPrometheusLabels(costLabels)
+			case "resourceID":
+				// add additional resource labels
+				costLabels = AzureResourceTagManager.AddToPrometheusLabels(costLabels)
+			}
+
+			costLabels = append(costLabels, dimension.Label)
+		}
+
+		// add additional export labels
+		for labelName := range export.Labels {
+			costLabels = append(costLabels, labelName)
+		}
+
+		if export.Granularity == "Daily" || export.Granularity == "Monthly" {
+			costLabels = append(costLabels, "date", "dateISO")
+		}
+
+		exportGaugeVec := prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: export.GetMetricName(),
+				Help: export.GetMetricHelp(),
+			},
+			costLabels,
+		)
+		m.Collector.RegisterMetricList(
+			fmt.Sprintf(`export:%v`, export.Name),
+			exportGaugeVec,
+			true,
+		)
+	}
 }
 
 func (m *MetricsCollectorAzureRmCosts) Reset() {}
@@ -187,12 +270,31 @@ func (m *MetricsCollectorAzureRmCosts) Collect(callback chan<- func()) {
 	for _, row := range Config.Collectors.Costs.Queries {
 		query := row
 
-		exportType := armcostmanagement.ExportTypeActualCost
-		if strings.EqualFold(query.ExportType, "AmortizedCost") {
-			exportType = armcostmanagement.ExportTypeAmortizedCost
+		// scopes are discovered once per query (and shared by all export types)
+		scopeList := m.costQueryScopes(logger.With(zap.String("query", query.Name)), &query)
+
+		if query.IsExportTypeBoth() {
+			// run query for both export types (label exportType)
+			m.collectRunCostQuery(&query, scopeList, armcostmanagement.ExportTypeActualCost, callback)
+			m.collectRunCostQuery(&query, scopeList, armcostmanagement.ExportTypeAmortizedCost, callback)
+		} else {
+			exportType := armcostmanagement.ExportTypeActualCost
+			if strings.EqualFold(query.ExportType, "AmortizedCost") {
+				exportType = armcostmanagement.ExportTypeAmortizedCost
+			}
+
+			m.collectRunCostQuery(&query, scopeList, exportType, callback)
 		}
 
-		m.collectRunCostQuery(&query, exportType, callback)
+		if query.HasTrend() {
+			m.collectCostQueryTrend(&query)
+		}
+	}
+
+	// process cost exports
+	for _, row := range Config.Collectors.Costs.Exports {
+		export := row
+		m.collectCostExport(&export, callback)
 	}
 
 	// run budget collection
@@ -208,15 +310,49 @@ func (m *MetricsCollectorAzureRmCosts) Collect(callback chan<- func()) {
 	}
 }
 
-func (m *MetricsCollectorAzureRmCosts) collectRunCostQuery(query *config.CollectorCostsQuery, exportType armcostmanagement.ExportType, callback chan<- func()) {
+// costQueryScopes returns static and discovered scopes of query (deduplicated by scope ID)
+// nil is returned if query uses subscriptions instead of scopes
+func (m *MetricsCollectorAzureRmCosts) costQueryScopes(logger *zap.SugaredLogger, query *config.CollectorCostsQuery) []CostQueryScope {
+	if (query.Scopes == nil || len(*query.Scopes) == 0) && !query.HasScopeTemplates() {
+		return nil
+	}
+
+	scopeList := []CostQueryScope{}
+	if query.Scopes != nil {
+		for _, scope := range *query.Scopes {
+			scopeList = append(scopeList, CostQueryScope{Scope: scope})
+		}
+	}
+
+	if query.HasScopeTemplates() {
+		scopeList = append(scopeList, m.discoverCostQueryScopes(logger, query.ScopeTemplates)...)
+	}
+
+	// static scopes are first, so they win over discovered scopes
+	list := []CostQueryScope{}
+	scopeExists := map[string]bool{}
+	for _, scope := range scopeList {
+		scopeId := strings.ToLower(scope.Scope)
+		if scopeExists[scopeId] {
+			continue
+		}
+		scopeExists[scopeId] = true
+		list = append(list, scope)
+	}
+
+	return list
+}
+
+func (m *MetricsCollectorAzureRmCosts) collectRunCostQuery(query *config.CollectorCostsQuery, scopeList []CostQueryScope, exportType armcostmanagement.ExportType, callback chan<- func()) {
 	queryLogger := logger.With(zap.String("query", query.Name))
+
 	for _, timeframe := range query.TimeFrames {
 		timeframeLogger := queryLogger.With(zap.String("timeframe", timeframe))
-		if query.Scopes != nil && len(*query.Scopes) > 0 {
+		if scopeList != nil {
 			// using custom scope
-			for _, scope := range *query.Scopes {
+			for _, scope := range scopeList {
 				m.collectCostManagementMetrics(
-					timeframeLogger,
+					timeframeLogger.With(zap.String("scope", scope.Scope)),
 					m.Collector.GetMetricList(fmt.Sprintf(`query:%v`, query.Name)),
 					scope,
 					exportType,
@@ -237,7 +373,7 @@ func (m *MetricsCollectorAzureRmCosts) collectRunCostQuery(query *config.Collect
 				m.collectCostManagementMetrics(
 					subscriptionLogger,
 					m.Collector.GetMetricList(fmt.Sprintf(`query:%v`, query.Name)),
-					*subscription.ID,
+					CostQueryScope{Scope: *subscription.ID, Name: to.String(subscription.DisplayName)},
 					exportType,
 					query,
 					timeframe,
@@ -252,6 +388,156 @@ func (m *MetricsCollectorAzureRmCosts) collectRunCostQuery(query *config.Collect
 	}
 }
 
+// discoverCostQueryScopes discovers management group and billing profile scopes based on scope templates
+// discovery errors are logged (eg. missing billing permissions) so statically configured scopes are still queried
+func (m *MetricsCollectorAzureRmCosts) discoverCostQueryScopes(logger *zap.SugaredLogger, templates *config.CollectorCostsQueryScopeTemplates) (list []CostQueryScope) {
+	if templates.ManagementGroups != nil {
+		scopeList, err := m.discoverManagementGroupScopes(logger, *templates.ManagementGroups)
+		if err != nil {
+			logger.Errorf(`unable to discover management group scopes "%v": %v`, *templates.ManagementGroups, err.Error())
+		}
+		list = append(list, scopeList...)
+	}
+
+	if templates.BillingProfiles != nil {
+		scopeList, err := m.discoverBillingProfileScopes(logger, *templates.BillingProfiles)
+		if err != nil {
+			logger.Errorf(`unable to discover billing profile scopes "%v": %v`, *templates.BillingProfiles, err.Error())
+		}
+		list = append(list, scopeList...)
+	}
+
+	logger.Infof(`discovered %v cost query scopes`, len(list))
+
+	return
+}
+
+// discoverManagementGroupScopes lists all visible management groups or the given management group and all its descendant groups
+func (m *MetricsCollectorAzureRmCosts) discoverManagementGroupScopes(logger *zap.SugaredLogger, template string) (list []CostQueryScope, err error) {
+	client, err := armmanagementgroups.NewClient(AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	if config.IsScopeTemplateDiscoverAll(template) {
+		pager := client.NewListPager(nil)
+		for pager.More() {
+			result, err := pager.NextPage(m.Context())
+			if err != nil {
+				return nil, err
+			}
+
+			for _, managementGroup := range result.Value {
+				scope := CostQueryScope{
+					Scope: to.String(managementGroup.ID),
+					Name:  to.String(managementGroup.Name),
+				}
+				if managementGroup.Properties != nil && managementGroup.Properties.DisplayName != nil {
+					scope.Name = *managementGroup.Properties.DisplayName
+				}
+				list = append(list, scope)
+			}
+		}
+	} else {
+		managementGroup, err := client.Get(m.Context(), template, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		scope := CostQueryScope{
+			Scope: to.String(managementGroup.ID),
+			Name:  to.String(managementGroup.Name),
+		}
+		if managementGroup.Properties != nil && managementGroup.Properties.DisplayName != nil {
+			scope.Name = *managementGroup.Properties.DisplayName
+		}
+		list = append(list, scope)
+
+		pager := client.NewGetDescendantsPager(template, nil)
+		for pager.More() {
+			result, err := pager.NextPage(m.Context())
+			if err != nil {
+				return nil, err
+			}
+
+			for _, descendant := range result.Value {
+				// descendants also contain subscriptions
+				if !strings.HasSuffix(to.StringLower(descendant.Type), "microsoft.management/managementgroups") {
+					continue
+				}
+
+				scope := CostQueryScope{
+					Scope: to.String(descendant.ID),
+					Name:  to.String(descendant.Name),
+				}
+				if descendant.Properties != nil && descendant.Properties.DisplayName != nil {
+					scope.Name = *descendant.Properties.DisplayName
+				}
+				list = append(list, scope)
+			}
+		}
+	}
+
+	return list, nil
+}
+
+// discoverBillingProfileScopes lists all visible billing profiles or all billing profiles of the given billing account
+func (m *MetricsCollectorAzureRmCosts) discoverBillingProfileScopes(logger *zap.SugaredLogger, template string) (list []CostQueryScope, err error) {
+	billingAccountList := []string{}
+	if config.IsScopeTemplateDiscoverAll(template) {
+		billingAccounts, err := m.listBillingResources(logger, "/providers/Microsoft.Billing/billingAccounts")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, billingAccount := range billingAccounts {
+			billingAccountList = append(billingAccountList, billingAccount.ID)
+		}
+	} else {
+		billingAccountList = append(billingAccountList, "/providers/Microsoft.Billing/billingAccounts/"+url.PathEscape(template))
+	}
+
+	for _, billingAccountId := range billingAccountList {
+		billingProfiles, err := m.listBillingResources(logger, billingAccountId+"/billingProfiles")
+		if err != nil {
+			// eg. EA billing accounts don't have billing profiles
+			logger.Warnf(`unable to list billing profiles of "%v": %v`, billingAccountId, err.Error())
+			continue
+		}
+
+		for _, billingProfile := range billingProfiles {
+			scope := CostQueryScope{
+				Scope: billingProfile.ID,
+				Name:  billingProfile.Name,
+			}
+			if billingProfile.Properties.DisplayName != "" {
+				scope.Name = billingProfile.Properties.DisplayName
+			}
+			list = append(list, scope)
+		}
+	}
+
+	return list, nil
+}
+
+// listBillingResources lists billing resources (billingAccounts, billingProfiles) using Microsoft.Billing REST API
+func (m *MetricsCollectorAzureRmCosts) listBillingResources(logger *zap.SugaredLogger, urlPath string) (list []costBillingResource, err error) {
+	rows, err := listArmApiResources(m.Context(), urlPath, url.Values{"api-version": {"2020-05-01"}})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		billingResource := costBillingResource{}
+		if err := json.Unmarshal(row, &billingResource); err != nil {
+			return nil, err
+		}
+		list = append(list, billingResource)
+	}
+
+	return list, nil
+}
+
 func (m *MetricsCollectorAzureRmCosts) collectBudgetMetrics(logger *zap.SugaredLogger, subscription *armsubscriptions.Subscription) {
 	client, err := armconsumption.NewBudgetsClient(AzureClient.GetCred(), AzureClient.NewArmClientOptions())
 	if err != nil {
@@ -319,7 +605,7 @@ func (m *MetricsCollectorAzureRmCosts) collectBudgetMetrics(logger *zap.SugaredL
 	}
 }
 
-func (m *MetricsCollectorAzureRmCosts) collectCostManagementMetrics(logger *zap.SugaredLogger, metricList *collector.MetricList, scope string, exportType armcostmanagement.ExportType, query *config.CollectorCostsQuery, timeframe string, subscription *armsubscriptions.Subscription) {
+func (m *MetricsCollectorAzureRmCosts) collectCostManagementMetrics(logger *zap.SugaredLogger, metricList *collector.MetricList, scope CostQueryScope, exportType armcostmanagement.ExportType, query *config.CollectorCostsQuery, timeframe string, subscription *armsubscriptions.Subscription) {
 	logger.Infof(`fetching cost report for query "%v"`, query.Name)
 
 	queryConfig := query.GetConfig()
@@ -409,7 +695,7 @@ func (m *MetricsCollectorAzureRmCosts) collectCostManagementMetrics(logger *zap.
 		params.TimePeriod = &timePeriod
 	}
 
-	result, err := m.sendCostQuery(m.Context(), logger, scope, params, nil)
+	result, err := m.sendCostQuery(m.Context(), logger, scope.Scope, params, nil)
 	if err != nil {
 		logger.Panic(err)
 	}
@@ -476,13 +762,21 @@ func (m *MetricsCollectorAzureRmCosts) collectCostManagementMetrics(logger *zap.
 		}
 
 		labels := prometheus.Labels{
-			"scope":          scope,
+			"scope":          scope.Scope,
 			"subscriptionID": "",
 			"currency":       stringToStringLower(row[columnNumberCurrency].(string)),
 			"timeframe":      timeframe,
 			"granularity":    stringToStringLower(query.Granularity),
 		}
 
+		if query.HasScopeTemplates() {
+			labels["scopeName"] = scope.Name
+		}
+
+		if query.IsExportTypeBoth() {
+			labels["exportType"] = string(exportType)
+		}
+
 		if subscription != nil {
 			labels["subscriptionID"] = *subscription.SubscriptionID
 		}
@@ -0,0 +1,605 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"math/big"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/parquet-go/parquet-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/webdevops/azure-resourcemanager-exporter/config"
+)
+
+type (
+	// costExportFile is a single cost export file (local file or azure blob)
+	costExportFile struct {
+		Name         string
+		LastModified time.Time
+		open         func() (io.ReadCloser, error)
+	}
+
+	// costExportRow is a single row of a cost export file, column names are lowercase
+	costExportRow map[string]string
+
+	// costExportColumns are the (format specific) well known column names of an cost export
+	costExportColumns struct {
+		Currency     []string
+		Date         []string
+		Subscription []string
+		Tags         []string
+	}
+
+	costExportAggregation struct {
+		labels prometheus.Labels
+		value  float64
+	}
+)
+
+var (
+	// cost exports are stored in folders named by their date range (eg. 20240101-20240131)
+	costExportDateRangeFolderRegExp = regexp.MustCompile(`(^|/)[0-9]{8}-[0-9]{8}(/|$)`)
+
+	costExportColumnsUsage = costExportColumns{
+		Currency:     []string{"BillingCurrency", "BillingCurrencyCode", "Currency"},
+		Date:         []string{"Date", "UsageDateTime", "UsageDate"},
+		Subscription: []string{"SubscriptionId", "SubscriptionGuid"},
+		Tags:         []string{"Tags"},
+	}
+
+	costExportColumnsFocus = costExportColumns{
+		Currency:     []string{"BillingCurrency"},
+		Date:         []string{"ChargePeriodStart"},
+		Subscription: []string{"SubAccountId"},
+		Tags:         []string{"Tags"},
+	}
+
+	costExportDateFormats = []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+		"01/02/2006",
+		"20060102",
+	}
+)
+
+// collectCostExport reads Cost Management scheduled export files (usage details or FOCUS, csv or parquet)
+// from local directory or azure blob storage and aggregates them by the configured dimensions
+func (m *MetricsCollectorAzureRmCosts) collectCostExport(export *config.CollectorCostsExport, callback chan<- func()) {
+	exportLogger := m.Logger().With(zap.String("export", export.Name))
+	exportLogger.Infof(`processing cost export "%v"`, export.Name)
+
+	exportConfig := export.GetConfig()
+	metricList := m.Collector.GetMetricList(fmt.Sprintf(`export:%v`, export.Name))
+
+	// listing errors (eg. missing permissions on one storage account) only skip this export
+	fileList, err := m.listCostExportFiles(export.Path)
+	if err != nil {
+		exportLogger.Errorf(`unable to list files of cost export "%v": %v`, export.Name, err.Error())
+		return
+	}
+
+	if !export.AllRuns {
+		fileList = costExportFilterLatestRuns(fileList)
+	}
+
+	columns := costExportColumnsUsage
+	if export.GetFormat() == config.CostsExportFormatFocus {
+		columns = costExportColumnsFocus
+	}
+
+	var timePeriodFrom, timePeriodTo *time.Time
+	if export.TimePeriod != nil {
+		now := time.Now()
+		if export.TimePeriod.From != nil {
+			timePeriodFrom = export.TimePeriod.From
+		} else if export.TimePeriod.FromDuration != nil {
+			fromPeriod := now.Add(*export.TimePeriod.FromDuration)
+			timePeriodFrom = &fromPeriod
+		}
+
+		if export.TimePeriod.To != nil {
+			timePeriodTo = export.TimePeriod.To
+		} else if export.TimePeriod.ToDuration != nil {
+			toPeriod := now.Add(*export.TimePeriod.ToDuration)
+			timePeriodTo = &toPeriod
+		}
+	}
+
+	valueField := strings.ToLower(export.GetValueField())
+	aggregation := map[string]*costExportAggregation{}
+
+	// rows are aggregated per file first, so a corrupt file can be skipped without partial values
+	var fileAggregation map[string]*costExportAggregation
+
+	processRow := func(row costExportRow) {
+		value, err := strconv.ParseFloat(row[valueField], 64)
+		if err != nil {
+			// no cost value (eg. empty or unknown value column)
+			return
+		}
+
+		date, hasDate := row.Time(columns.Date...)
+		if timePeriodFrom != nil && (!hasDate || date.Before(*timePeriodFrom)) {
+			return
+		}
+		if timePeriodTo != nil && (!hasDate || date.After(*timePeriodTo)) {
+			return
+		}
+
+		subscriptionId := strings.ToLower(row.Get(columns.Subscription...))
+		subscriptionId = strings.TrimPrefix(subscriptionId, "/subscriptions/")
+
+		labels := prometheus.Labels{
+			"scope":          export.Path,
+			"subscriptionID": subscriptionId,
+			"currency":       strings.ToLower(row.Get(columns.Currency...)),
+			"timeframe":      "export",
+			"granularity":    strings.ToLower(export.Granularity),
+		}
+
+		switch export.Granularity {
+		case "Daily", "Monthly":
+			labels["date"] = ""
+			labels["dateISO"] = ""
+			if hasDate {
+				date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+				if export.Granularity == "Monthly" {
+					date = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+				}
+				labels["date"] = strconv.FormatInt(date.Unix(), 10)
+				labels["dateISO"] = date.Format(time.RFC3339)
+			}
+		}
+
+		var tags map[string]string
+		for _, dimension := range exportConfig.Dimensions {
+			if strings.HasPrefix(strings.ToLower(dimension.Dimension), "tag:") {
+				if tags == nil {
+					tags = row.Tags(columns.Tags...)
+				}
+				labels[dimension.Label] = tags[strings.ToLower(dimension.Dimension[4:])]
+			} else {
+				labels[dimension.Label] = row.Get(dimension.Dimension)
+			}
+		}
+
+		for labelName, labelValue := range export.Labels {
+			labels[labelName] = labelValue
+		}
+
+		key := prometheusLabelsKey(labels)
+		if entry, exists := fileAggregation[key]; exists {
+			entry.value += value
+		} else {
+			fileAggregation[key] = &costExportAggregation{labels: labels, value: value}
+		}
+	}
+
+	for _, file := range fileList {
+		fileLogger := exportLogger.With(zap.String("file", file.Name))
+		fileLogger.Debugf(`reading cost export file "%v"`, file.Name)
+
+		fileAggregation = map[string]*costExportAggregation{}
+		if err := readCostExportFile(file, processRow); err != nil {
+			fileLogger.Errorf(`unable to read cost export file "%v", skipping file: %v`, file.Name, err.Error())
+			continue
+		}
+
+		for key, row := range fileAggregation {
+			if entry, exists := aggregation[key]; exists {
+				entry.value += row.value
+			} else {
+				aggregation[key] = row
+			}
+		}
+	}
+
+	exportLogger.Infof(`aggregated %v rows from %v cost export files`, len(aggregation), len(fileList))
+
+	for _, row := range aggregation {
+		labels := row.labels
+		for _, dimension := range exportConfig.Dimensions {
+			switch dimension.Label {
+			case "resourceGroup":
+				resourceId := ""
+				if labels["subscriptionID"] != "" && labels["resourceGroup"] != "" {
+					// add resourceGroups labels using tag manager
+					resourceId = fmt.Sprintf(
+						"/subscriptions/%s/resourceGroups/%s",
+						labels["subscriptionID"],
+						labels["resourceGroup"],
+					)
+				}
+				labels = AzureResourceGroupTagManager.AddResourceTagsToPrometheusLabels(m.Context(), labels, resourceId)
+			case "resourceID":
+				// add resource labels using tag manager
+				labels = AzureResourceTagManager.AddResourceTagsToPrometheusLabels(m.Context(), labels, labels["resourceID"])
+			}
+		}
+
+		metricList.Add(labels, row.value)
+	}
+}
+
+// listCostExportFiles lists all export files from local path (/path or file:///path)
+// or azure blob storage (azblob://storageaccount.blob.core.windows.net/container/prefix)
+func (m *MetricsCollectorAzureRmCosts) listCostExportFiles(exportPath string) (list []costExportFile, err error) {
+	switch {
+	case strings.HasPrefix(exportPath, "azblob://"):
+		parsedUrl, err := url.Parse(exportPath)
+		if err != nil {
+			return list, err
+		}
+
+		pathParts := strings.SplitN(strings.TrimLeft(parsedUrl.Path, "/"), "/", 2)
+		if pathParts[0] == "" {
+			return list, fmt.Errorf(`azblob path needs to be specified as azblob://storageaccount.blob.core.windows.net/container/prefix, got: %v`, exportPath)
+		}
+
+		containerName := pathParts[0]
+		prefix := ""
+		if len(pathParts) == 2 {
+			prefix = pathParts[1]
+		}
+
+		azblobOpts := azblob.ClientOptions{ClientOptions: *AzureClient.NewAzCoreClientOptions()}
+		client, err := azblob.NewClient(fmt.Sprintf(`https://%v/`, parsedUrl.Hostname()), AzureClient.GetCred(), &azblobOpts)
+		if err != nil {
+			return list, err
+		}
+
+		pager := client.NewListBlobsFlatPager(containerName, &azblob.ListBlobsFlatOptions{Prefix: &prefix})
+		for pager.More() {
+			result, err := pager.NextPage(m.Context())
+			if err != nil {
+				return list, err
+			}
+
+			for _, blob := range result.Segment.BlobItems {
+				if blob.Name == nil || !isCostExportFile(*blob.Name) {
+					continue
+				}
+
+				blobName := *blob.Name
+				file := costExportFile{
+					Name: blobName,
+					open: func() (io.ReadCloser, error) {
+						response, err := client.DownloadStream(m.Context(), containerName, blobName, nil)
+						if err != nil {
+							return nil, err
+						}
+						return response.Body, nil
+					},
+				}
+				if blob.Properties != nil && blob.Properties.LastModified != nil {
+					file.LastModified = *blob.Properties.LastModified
+				}
+				list = append(list, file)
+			}
+		}
+	default:
+		rootPath := strings.TrimPrefix(exportPath, "file://")
+		err = filepath.WalkDir(rootPath, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() || !isCostExportFile(filePath) {
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+
+			relPath, err := filepath.Rel(rootPath, filePath)
+			if err != nil {
+				return err
+			}
+
+			list = append(list, costExportFile{
+				Name:         filepath.ToSlash(relPath),
+				LastModified: info.ModTime(),
+				open: func() (io.ReadCloser, error) {
+					return os.Open(filePath) // #nosec G304 path is configured by user
+				},
+			})
+			return nil
+		})
+	}
+
+	return
+}
+
+// costExportFilterLatestRuns filters the file list so only the latest export run per date range folder is used
+// (scheduled exports are creating a new run every day inside the same date range folder which would be counted twice)
+func costExportFilterLatestRuns(fileList []costExportFile) (list []costExportFile) {
+	latestFilePerRange := map[string]costExportFile{}
+	for _, file := range fileList {
+		match := costExportDateRangeFolderRegExp.FindStringIndex(file.Name)
+		if match == nil {
+			// not within a date range folder, always used
+			list = append(list, file)
+			continue
+		}
+
+		dateRangeFolder := file.Name[:match[1]]
+		if latestFile, exists := latestFilePerRange[dateRangeFolder]; !exists || file.LastModified.After(latestFile.LastModified) {
+			latestFilePerRange[dateRangeFolder] = file
+		}
+	}
+
+	for dateRangeFolder, latestFile := range latestFilePerRange {
+		runFolder := path.Dir(latestFile.Name) + "/"
+		if runFolder == dateRangeFolder {
+			// export without run folder (one file per run)
+			list = append(list, latestFile)
+			continue
+		}
+
+		// partitioned export, use all files of the run folder
+		for _, file := range fileList {
+			if path.Dir(file.Name)+"/" == runFolder {
+				list = append(list, file)
+			}
+		}
+	}
+
+	return
+}
+
+// isCostExportFile checks if file is a supported cost export file (csv, csv.gz, parquet)
+func isCostExportFile(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, ".csv") || strings.HasSuffix(name, ".csv.gz") || strings.HasSuffix(name, ".parquet")
+}
+
+// readCostExportFile reads all rows of an export file and passes them to the callback
+func readCostExportFile(file costExportFile, callback func(row costExportRow)) error {
+	reader, err := file.open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close() // nolint:errcheck
+
+	fileName := strings.ToLower(file.Name)
+	switch {
+	case strings.HasSuffix(fileName, ".parquet"):
+		return readCostExportParquet(reader, callback)
+	case strings.HasSuffix(fileName, ".gz"):
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close() // nolint:errcheck
+		return readCostExportCsv(gzipReader, callback)
+	default:
+		return readCostExportCsv(reader, callback)
+	}
+}
+
+// readCostExportCsv reads export csv files, first line is the header
+func readCostExportCsv(reader io.Reader, callback func(row costExportRow)) error {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	csvReader.ReuseRecord = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	columnNames := make([]string, len(header))
+	for num, name := range header {
+		// strip utf8 bom
+		columnNames[num] = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "\ufeff"))
+	}
+
+	for {
+		record, err := csvReader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		row := costExportRow{}
+		for num, value := range record {
+			if num < len(columnNames) {
+				row[columnNames[num]] = value
+			}
+		}
+		callback(row)
+	}
+}
+
+// readCostExportParquet reads export parquet files, only top level columns are used
+// parquet needs random access, so non local files (eg. azure blobs) are downloaded to a temporary file first
+func readCostExportParquet(reader io.Reader, callback func(row costExportRow)) error {
+	localFile, ok := reader.(*os.File)
+	if !ok {
+		tempFile, err := os.CreateTemp("", "azurerm-costexport-*.parquet")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tempFile.Name()) // nolint:errcheck
+		defer tempFile.Close()           // nolint:errcheck
+
+		if _, err := io.Copy(tempFile, reader); err != nil {
+			return err
+		}
+		localFile = tempFile
+	}
+
+	fileInfo, err := localFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	file, err := parquet.OpenFile(localFile, fileInfo.Size())
+	if err != nil {
+		return err
+	}
+
+	schema := file.Schema()
+	columnNames := map[int]string{}
+	columnNodes := map[int]parquet.Node{}
+	for _, columnPath := range schema.Columns() {
+		if leaf, ok := schema.Lookup(columnPath...); ok && len(columnPath) == 1 {
+			columnNames[leaf.ColumnIndex] = strings.ToLower(columnPath[0])
+			columnNodes[leaf.ColumnIndex] = leaf.Node
+		}
+	}
+
+	parquetReader := parquet.NewReader(file)
+	defer parquetReader.Close() // nolint:errcheck
+
+	rows := make([]parquet.Row, 100)
+	for {
+		count, err := parquetReader.ReadRows(rows)
+		for _, parquetRow := range rows[:count] {
+			row := costExportRow{}
+			for _, value := range parquetRow {
+				if columnName, exists := columnNames[value.Column()]; exists && !value.IsNull() {
+					row[columnName] = parquetValueToString(value, columnNodes[value.Column()])
+				}
+			}
+			callback(row)
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// parquetValueToString converts parquet values to strings (incl. decimal and timestamp logical types)
+func parquetValueToString(value parquet.Value, node parquet.Node) string {
+	logicalType := node.Type().LogicalType()
+
+	switch {
+	case logicalType != nil && logicalType.Decimal != nil:
+		unscaled := big.NewInt(0)
+		switch value.Kind() {
+		case parquet.Int32:
+			unscaled.SetInt64(int64(value.Int32()))
+		case parquet.Int64:
+			unscaled.SetInt64(value.Int64())
+		case parquet.ByteArray, parquet.FixedLenByteArray:
+			// big-endian two's complement
+			raw := value.ByteArray()
+			unscaled.SetBytes(raw)
+			if len(raw) > 0 && raw[0]&0x80 != 0 {
+				unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(raw)*8)))
+			}
+		}
+		result, _ := new(big.Float).Quo(
+			new(big.Float).SetInt(unscaled),
+			big.NewFloat(math.Pow10(int(logicalType.Decimal.Scale))),
+		).Float64()
+		return strconv.FormatFloat(result, 'f', -1, 64)
+	case logicalType != nil && logicalType.Timestamp != nil:
+		switch unit := logicalType.Timestamp.Unit; {
+		case unit.Millis != nil:
+			return time.UnixMilli(value.Int64()).UTC().Format(time.RFC3339)
+		case unit.Micros != nil:
+			return time.UnixMicro(value.Int64()).UTC().Format(time.RFC3339)
+		default:
+			return time.Unix(0, value.Int64()).UTC().Format(time.RFC3339)
+		}
+	case logicalType != nil && logicalType.Date != nil:
+		return time.Unix(int64(value.Int32())*86400, 0).UTC().Format(time.RFC3339)
+	}
+
+	switch value.Kind() {
+	case parquet.Float:
+		return strconv.FormatFloat(float64(value.Float()), 'f', -1, 32)
+	case parquet.Double:
+		return strconv.FormatFloat(value.Double(), 'f', -1, 64)
+	case parquet.Int32:
+		return strconv.FormatInt(int64(value.Int32()), 10)
+	case parquet.Int64:
+		return strconv.FormatInt(value.Int64(), 10)
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		return string(value.ByteArray())
+	default:
+		return value.String()
+	}
+}
+
+// Get returns the value of the first existing column
+func (row costExportRow) Get(columnNames ...string) string {
+	for _, columnName := range columnNames {
+		if val, exists := row[strings.ToLower(columnName)]; exists {
+			return val
+		}
+	}
+	return ""
+}
+
+// Time returns the parsed time of the first existing column
+func (row costExportRow) Time(columnNames ...string) (time.Time, bool) {
+	val := strings.TrimSpace(row.Get(columnNames...))
+	if val == "" {
+		return time.Time{}, false
+	}
+
+	for _, dateFormat := range costExportDateFormats {
+		if date, err := time.Parse(dateFormat, val); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Tags returns the parsed tags (lowercase tag names) of the first existing column
+// usage exports are using `"key": "value","key2": "value2"` and FOCUS exports json objects
+func (row costExportRow) Tags(columnNames ...string) map[string]string {
+	tags := map[string]string{}
+
+	val := strings.TrimSpace(row.Get(columnNames...))
+	if val == "" {
+		return tags
+	}
+
+	if !strings.HasPrefix(val, "{") {
+		val = "{" + val + "}"
+	}
+
+	rawTags := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(val), &rawTags); err == nil {
+		for tagName, tagValue := range rawTags {
+			tags[strings.ToLower(tagName)] = fmt.Sprintf("%v", tagValue)
+		}
+	}
+
+	return tags
+}
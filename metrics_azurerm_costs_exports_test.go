@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestCostExportFile(name string, lastModified time.Time) costExportFile {
+	return costExportFile{
+		Name:         name,
+		LastModified: lastModified,
+		open: func() (io.ReadCloser, error) {
+			return os.Open(filepath.Join("testdata", "costexports", name)) // #nosec G304 test fixture
+		},
+	}
+}
+
+func TestReadCostExportFile(t *testing.T) {
+	testCases := []struct {
+		file     string
+		expected []costExportRow
+	}{
+		{
+			file: "usage.csv",
+			expected: []costExportRow{
+				{"date": "01/15/2024", "subscriptionid": "00000000-0000-0000-0000-000000000001", "resourcegroup": "rg-app", "costinbillingcurrency": "1.5", "billingcurrency": "EUR", "tags": `"Owner": "team-a","env": "prod"`},
+				{"date": "01/16/2024", "subscriptionid": "00000000-0000-0000-0000-000000000001", "resourcegroup": "rg-app", "costinbillingcurrency": "2.25", "billingcurrency": "EUR", "tags": ""},
+			},
+		},
+		{
+			file: "usage.csv.gz",
+			expected: []costExportRow{
+				{"date": "01/15/2024", "subscriptionid": "00000000-0000-0000-0000-000000000001", "resourcegroup": "rg-app", "costinbillingcurrency": "1.5", "billingcurrency": "EUR", "tags": `"Owner": "team-a","env": "prod"`},
+				{"date": "01/16/2024", "subscriptionid": "00000000-0000-0000-0000-000000000001", "resourcegroup": "rg-app", "costinbillingcurrency": "2.25", "billingcurrency": "EUR", "tags": ""},
+			},
+		},
+		{
+			file: "focus.parquet",
+			expected: []costExportRow{
+				{"chargeperiodstart": "2024-01-15T00:00:00Z", "subaccountid": "/subscriptions/00000000-0000-0000-0000-000000000001", "billedcost": "1.5", "effectivecost": "1.25", "billingcurrency": "EUR", "tags": `{"Owner":"team-a","env":"prod"}`},
+				{"chargeperiodstart": "2024-01-16T00:00:00Z", "subaccountid": "/subscriptions/00000000-0000-0000-0000-000000000001", "billedcost": "-0.25", "effectivecost": "0.5", "billingcurrency": "EUR"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		rows := []costExportRow{}
+		err := readCostExportFile(newTestCostExportFile(testCase.file, time.Time{}), func(row costExportRow) {
+			rows = append(rows, row)
+		})
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", testCase.file, err)
+			continue
+		}
+
+		assertCostExportRows(t, testCase.file, testCase.expected, rows)
+	}
+}
+
+func TestReadCostExportParquetFromStream(t *testing.T) {
+	// non local files (eg. azure blobs) are downloaded to a temporary file
+	content, err := os.ReadFile(filepath.Join("testdata", "costexports", "focus.parquet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	if err := readCostExportParquet(bytes.NewReader(content), func(row costExportRow) { count++ }); err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 2 {
+		t.Errorf("expected 2 rows, got %v", count)
+	}
+
+	if err := readCostExportParquet(strings.NewReader("invalid"), func(row costExportRow) {}); err == nil {
+		t.Error("expected error for invalid parquet file")
+	}
+}
+
+func TestCostExportRowTags(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected map[string]string
+	}{
+		{value: `"Owner": "team-a","env": "prod"`, expected: map[string]string{"owner": "team-a", "env": "prod"}},
+		{value: `{"Owner":"team-a","count":2}`, expected: map[string]string{"owner": "team-a", "count": "2"}},
+		{value: ` `, expected: map[string]string{}},
+		{value: `invalid`, expected: map[string]string{}},
+	}
+
+	for _, testCase := range testCases {
+		tags := costExportRow{"tags": testCase.value}.Tags("Tags")
+		if len(tags) != len(testCase.expected) {
+			t.Errorf("%q: expected tags %v, got %v", testCase.value, testCase.expected, tags)
+			continue
+		}
+		for tagName, tagValue := range testCase.expected {
+			if tags[tagName] != tagValue {
+				t.Errorf("%q: expected tag %v=%q, got %q", testCase.value, tagName, tagValue, tags[tagName])
+			}
+		}
+	}
+}
+
+func TestCostExportRowTime(t *testing.T) {
+	expected := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		value string
+		valid bool
+	}{
+		{value: "2024-01-15T00:00:00Z", valid: true},
+		{value: "2024-01-15T00:00:00", valid: true},
+		{value: "2024-01-15 00:00:00", valid: true},
+		{value: "2024-01-15", valid: true},
+		{value: "01/15/2024", valid: true},
+		{value: "20240115", valid: true},
+		{value: " 2024-01-15 ", valid: true},
+		{value: "15.01.2024", valid: false},
+		{value: "", valid: false},
+	}
+
+	for _, testCase := range testCases {
+		date, ok := costExportRow{"usagedate": testCase.value}.Time("Date", "UsageDate")
+		if ok != testCase.valid {
+			t.Errorf("%q: expected valid=%v, got %v", testCase.value, testCase.valid, ok)
+			continue
+		}
+		if ok && !date.Equal(expected) {
+			t.Errorf("%q: expected %v, got %v", testCase.value, expected, date)
+		}
+	}
+}
+
+func TestCostExportFilterLatestRuns(t *testing.T) {
+	day := func(num int) time.Time {
+		return time.Date(2024, 1, num, 0, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name     string
+		files    []costExportFile
+		expected []string
+	}{
+		{
+			name: "one file per run",
+			files: []costExportFile{
+				{Name: "export/20240101-20240131/export_1.csv", LastModified: day(2)},
+				{Name: "export/20240101-20240131/export_2.csv", LastModified: day(3)},
+				{Name: "export/20240201-20240229/export_1.csv", LastModified: day(1)},
+			},
+			expected: []string{
+				"export/20240101-20240131/export_2.csv",
+				"export/20240201-20240229/export_1.csv",
+			},
+		},
+		{
+			name: "partitioned runs",
+			files: []costExportFile{
+				{Name: "export/20240101-20240131/run-1/part_0.csv", LastModified: day(2)},
+				{Name: "export/20240101-20240131/run-1/part_1.csv", LastModified: day(2)},
+				{Name: "export/20240101-20240131/run-2/part_0.csv", LastModified: day(3)},
+				{Name: "export/20240101-20240131/run-2/part_1.csv", LastModified: day(3)},
+			},
+			expected: []string{
+				"export/20240101-20240131/run-2/part_0.csv",
+				"export/20240101-20240131/run-2/part_1.csv",
+			},
+		},
+		{
+			name: "files without date range folder",
+			files: []costExportFile{
+				{Name: "export/manual.csv", LastModified: day(1)},
+				{Name: "20240101-20240131.csv", LastModified: day(2)},
+			},
+			expected: []string{
+				"20240101-20240131.csv",
+				"export/manual.csv",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		names := []string{}
+		for _, file := range costExportFilterLatestRuns(testCase.files) {
+			names = append(names, file.Name)
+		}
+		sort.Strings(names)
+
+		if strings.Join(names, ",") != strings.Join(testCase.expected, ",") {
+			t.Errorf("%v: expected %v, got %v", testCase.name, testCase.expected, names)
+		}
+	}
+}
+
+func assertCostExportRows(t *testing.T, name string, expected, actual []costExportRow) {
+	t.Helper()
+
+	if len(expected) != len(actual) {
+		t.Errorf("%v: expected %v rows, got %v", name, len(expected), len(actual))
+		return
+	}
+
+	for num, expectedRow := range expected {
+		if len(expectedRow) != len(actual[num]) {
+			t.Errorf("%v: row %v: expected %v, got %v", name, num, expectedRow, actual[num])
+			continue
+		}
+		for columnName, value := range expectedRow {
+			if actual[num][columnName] != value {
+				t.Errorf("%v: row %v: expected column %v=%q, got %q", name, num, columnName, value, actual[num][columnName])
+			}
+		}
+	}
+}
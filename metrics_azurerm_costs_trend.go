@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/webdevops/azure-resourcemanager-exporter/config"
+)
+
+type (
+	// costTrendSeries contains daily cost values (by unix timestamp of day) of one label set (without date labels)
+	costTrendSeries struct {
+		labels prometheus.Labels
+		values map[int64]float64
+	}
+)
+
+const (
+	costTrendDay = int64(24 * 60 * 60)
+)
+
+// setupCostQueryTrend registers trend and anomaly metrics for daily cost queries
+func (m *MetricsCollectorAzureRmCosts) setupCostQueryTrend(query config.CollectorCostsQuery, labels []string) {
+	metricName := query.GetMetricName()
+
+	m.Collector.RegisterMetricList(
+		fmt.Sprintf(`queryTrend:%v:delta1d`, query.Name),
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: metricName + "_delta_1d",
+				Help: "Azure ResourceManager costmanagement query day-over-day cost delta of latest complete day",
+			},
+			labels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		fmt.Sprintf(`queryTrend:%v:delta7d`, query.Name),
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: metricName + "_delta_7d",
+				Help: "Azure ResourceManager costmanagement query week-over-week cost delta of latest complete day",
+			},
+			labels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		fmt.Sprintf(`queryTrend:%v:avg7d`, query.Name),
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: metricName + "_avg_7d",
+				Help: "Azure ResourceManager costmanagement query rolling 7 day average of daily costs",
+			},
+			labels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		fmt.Sprintf(`queryTrend:%v:anomalyScore`, query.Name),
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: metricName + "_anomaly_score",
+				Help: "Azure ResourceManager costmanagement query z-score of latest complete day compared to the previous days",
+			},
+			labels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		fmt.Sprintf(`queryTrend:%v:anomaly`, query.Name),
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: metricName + "_anomaly",
+				Help: "Azure ResourceManager costmanagement query anomaly flag (anomaly score exceeds threshold)",
+			},
+			labels,
+		),
+		true,
+	)
+}
+
+// collectCostQueryTrend calculates trend and anomaly metrics from the daily query results
+func (m *MetricsCollectorAzureRmCosts) collectCostQueryTrend(query *config.CollectorCostsQuery) {
+	delta1dMetric := m.Collector.GetMetricList(fmt.Sprintf(`queryTrend:%v:delta1d`, query.Name))
+	delta7dMetric := m.Collector.GetMetricList(fmt.Sprintf(`queryTrend:%v:delta7d`, query.Name))
+	avg7dMetric := m.Collector.GetMetricList(fmt.Sprintf(`queryTrend:%v:avg7d`, query.Name))
+	anomalyScoreMetric := m.Collector.GetMetricList(fmt.Sprintf(`queryTrend:%v:anomalyScore`, query.Name))
+	anomalyMetric := m.Collector.GetMetricList(fmt.Sprintf(`queryTrend:%v:anomaly`, query.Name))
+
+	// group daily values by label set (without date)
+	seriesList := map[string]*costTrendSeries{}
+	for _, row := range m.Collector.GetMetricList(fmt.Sprintf(`query:%v`, query.Name)).GetList() {
+		date, err := strconv.ParseInt(row.Labels["date"], 10, 64)
+		if err != nil || date <= 0 {
+			continue
+		}
+
+		labels := prometheus.Labels{}
+		for labelName, labelValue := range row.Labels {
+			if labelName != "date" && labelName != "dateISO" {
+				labels[labelName] = labelValue
+			}
+		}
+
+		key := prometheusLabelsKey(labels)
+		if _, exists := seriesList[key]; !exists {
+			seriesList[key] = &costTrendSeries{labels: labels, values: map[int64]float64{}}
+		}
+		seriesList[key].values[date-date%costTrendDay] += row.Value
+	}
+
+	// current day is not complete yet, all series are anchored on the last complete day
+	today := time.Now().UTC().Unix()
+	today -= today % costTrendDay
+	latestDay := today - costTrendDay
+
+	window := int64(query.GetTrendWindow())
+	threshold := query.GetTrendThreshold()
+
+	for _, series := range seriesList {
+		// missing days are days without costs
+		value := func(day int64) float64 {
+			return series.values[day]
+		}
+
+		latestValue := value(latestDay)
+
+		delta1dMetric.Add(series.labels, latestValue-value(latestDay-costTrendDay))
+		delta7dMetric.Add(series.labels, latestValue-value(latestDay-7*costTrendDay))
+
+		sum := float64(0)
+		for day := latestDay - 6*costTrendDay; day <= latestDay; day += costTrendDay {
+			sum += value(day)
+		}
+		avg7dMetric.Add(series.labels, sum/7)
+
+		// z-score of latest day based on the previous days (window)
+		previousValues := []float64{}
+		for day := latestDay - window*costTrendDay; day < latestDay; day += costTrendDay {
+			previousValues = append(previousValues, value(day))
+		}
+
+		mean, stddev := costTrendMeanStdDev(previousValues)
+
+		score := float64(0)
+		if stddev > 0 {
+			score = (latestValue - mean) / stddev
+		}
+
+		anomalyScoreMetric.Add(series.labels, score)
+		anomalyMetric.AddBool(series.labels, math.Abs(score) >= threshold)
+	}
+}
+
+// costTrendMeanStdDev calculates mean and (population) standard deviation
+func costTrendMeanStdDev(values []float64) (mean, stddev float64) {
+	for _, val := range values {
+		mean += val
+	}
+	mean /= float64(len(values))
+
+	for _, val := range values {
+		stddev += math.Pow(val-mean, 2)
+	}
+	stddev = math.Sqrt(stddev / float64(len(values)))
+
+	return
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/azuresdk/armclient"
+	"github.com/webdevops/go-common/prometheus/collector"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+
+	"github.com/webdevops/azure-resourcemanager-exporter/config"
+)
+
+const (
+	// Azure keeps max 800 deployments in deployment history per scope
+	DeploymentHistoryLimit = 800
+
+	DeploymentScopeTypeResourceGroup   = "resourceGroup"
+	DeploymentScopeTypeSubscription    = "subscription"
+	DeploymentScopeTypeManagementGroup = "managementGroup"
+)
+
+var (
+	iso8601DurationRegExp = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:([\d.]+)S)?)?$`)
+)
+
+type MetricsCollectorAzureRmDeployment struct {
+	collector.Processor
+
+	prometheus struct {
+		deploymentInfo         *prometheus.GaugeVec
+		deploymentTimestamp    *prometheus.GaugeVec
+		deploymentDuration     *prometheus.GaugeVec
+		deploymentCount        *prometheus.GaugeVec
+		deploymentHistoryCount *prometheus.GaugeVec
+		deploymentHistoryUsage *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorAzureRmDeployment) Setup(collector *collector.Collector) {
+	m.Processor.Setup(collector)
+
+	m.prometheus.deploymentInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_deployment_info",
+			Help: "Azure ResourceManager deployment information",
+		},
+		[]string{
+			"deploymentID",
+			"deploymentName",
+			"scope",
+			"scopeType",
+			"subscriptionID",
+			"resourceGroup",
+			"provisioningState",
+			"mode",
+			"templateHash",
+			"errorCode",
+		},
+	)
+	m.Collector.RegisterMetricList("deploymentInfo", m.prometheus.deploymentInfo, true)
+
+	m.prometheus.deploymentTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_deployment_timestamp",
+			Help: "Azure ResourceManager deployment timestamp",
+		},
+		[]string{
+			"deploymentID",
+		},
+	)
+	m.Collector.RegisterMetricList("deploymentTimestamp", m.prometheus.deploymentTimestamp, true)
+
+	m.prometheus.deploymentDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_deployment_duration_seconds",
+			Help: "Azure ResourceManager deployment duration in seconds",
+		},
+		[]string{
+			"deploymentID",
+		},
+	)
+	m.Collector.RegisterMetricList("deploymentDuration", m.prometheus.deploymentDuration, true)
+
+	m.prometheus.deploymentCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_deployment_count",
+			Help: "Azure ResourceManager deployment count in deployment history per scope and provisioningState",
+		},
+		[]string{
+			"scope",
+			"scopeType",
+			"subscriptionID",
+			"resourceGroup",
+			"provisioningState",
+		},
+	)
+	m.Collector.RegisterMetricList("deploymentCount", m.prometheus.deploymentCount, true)
+
+	m.prometheus.deploymentHistoryCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_deployment_history_count",
+			Help: "Azure ResourceManager deployment history count per scope",
+		},
+		[]string{
+			"scope",
+			"scopeType",
+			"subscriptionID",
+			"resourceGroup",
+		},
+	)
+	m.Collector.RegisterMetricList("deploymentHistoryCount", m.prometheus.deploymentHistoryCount, true)
+
+	m.prometheus.deploymentHistoryUsage = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_deployment_history_usage",
+			Help: "Azure ResourceManager deployment history usage ratio (0-1) of the 800 deployments limit per scope",
+		},
+		[]string{
+			"scope",
+			"scopeType",
+			"subscriptionID",
+			"resourceGroup",
+		},
+	)
+	m.Collector.RegisterMetricList("deploymentHistoryUsage", m.prometheus.deploymentHistoryUsage, true)
+}
+
+func (m *MetricsCollectorAzureRmDeployment) Reset() {}
+
+func (m *MetricsCollectorAzureRmDeployment) Collect(callback chan<- func()) {
+	err := AzureSubscriptionsIterator.ForEachAsync(m.Logger(), func(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+		m.collectSubscriptionDeployments(subscription, logger)
+	})
+	if err != nil {
+		m.Logger().Panic(err)
+	}
+
+	for _, managementGroupId := range m.deploymentManagementGroups() {
+		m.collectManagementGroupDeployments(managementGroupId, m.Logger().With(zap.String("managementGroup", managementGroupId)))
+	}
+}
+
+// deploymentManagementGroups returns configured management groups, "all" is replaced by all management groups
+// of the (shared) management group hierarchy
+func (m *MetricsCollectorAzureRmDeployment) deploymentManagementGroups() (list []string) {
+	managementGroupExists := map[string]bool{}
+	addManagementGroup := func(managementGroupId string) {
+		if !managementGroupExists[strings.ToLower(managementGroupId)] {
+			managementGroupExists[strings.ToLower(managementGroupId)] = true
+			list = append(list, managementGroupId)
+		}
+	}
+
+	for _, managementGroupId := range Config.Collectors.Deployment.ManagementGroups {
+		if !config.IsScopeTemplateDiscoverAll(managementGroupId) {
+			addManagementGroup(managementGroupId)
+			continue
+		}
+
+		hierarchy, err := cachedManagementGroupHierarchy(m.Context())
+		if err != nil {
+			m.Logger().Errorf(`unable to discover management groups: %v`, err.Error())
+			continue
+		}
+
+		discoveredList := []string{}
+		for discoveredId := range hierarchy.groups {
+			discoveredList = append(discoveredList, discoveredId)
+		}
+		sort.Strings(discoveredList)
+
+		for _, discoveredId := range discoveredList {
+			addManagementGroup(discoveredId)
+		}
+	}
+
+	return
+}
+
+func (m *MetricsCollectorAzureRmDeployment) collectSubscriptionDeployments(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	client, err := armresources.NewDeploymentsClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	subscriptionScope := "/subscriptions/" + to.StringLower(subscription.SubscriptionID)
+
+	// subscription scope
+	deploymentList := []*armresources.DeploymentExtended{}
+	pager := client.NewListAtSubscriptionScopePager(nil)
+	for pager.More() {
+		result, err := pager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+		deploymentList = append(deploymentList, result.Value...)
+	}
+	m.addDeployments(subscriptionScope, DeploymentScopeTypeSubscription, deploymentList)
+
+	// resourcegroup scope
+	resourceGroupList, err := AzureClient.ListResourceGroups(m.Context(), *subscription.SubscriptionID)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	for resourceGroupName, resourceGroup := range resourceGroupList {
+		deploymentList, err := m.listResourceGroupDeployments(client, resourceGroupName)
+		if err != nil {
+			// partial history would report wrong counts and usage, skip all series of this resourcegroup
+			logger.Warnf(`unable to list deployments of resourcegroup "%v": %v`, resourceGroupName, err.Error())
+			continue
+		}
+		m.addDeployments(to.StringLower(resourceGroup.ID), DeploymentScopeTypeResourceGroup, deploymentList)
+	}
+}
+
+// listResourceGroupDeployments lists all deployments of resourcegroup
+func (m *MetricsCollectorAzureRmDeployment) listResourceGroupDeployments(client *armresources.DeploymentsClient, resourceGroupName string) ([]*armresources.DeploymentExtended, error) {
+	deploymentList := []*armresources.DeploymentExtended{}
+	pager := client.NewListByResourceGroupPager(resourceGroupName, nil)
+	for pager.More() {
+		result, err := pager.NextPage(m.Context())
+		if err != nil {
+			return nil, err
+		}
+		deploymentList = append(deploymentList, result.Value...)
+	}
+	return deploymentList, nil
+}
+
+func (m *MetricsCollectorAzureRmDeployment) collectManagementGroupDeployments(managementGroupId string, logger *zap.SugaredLogger) {
+	// subscriptionID is not used for management group scope
+	client, err := armresources.NewDeploymentsClient("", AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	deploymentList := []*armresources.DeploymentExtended{}
+	pager := client.NewListAtManagementGroupScopePager(managementGroupId, nil)
+	for pager.More() {
+		result, err := pager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+		deploymentList = append(deploymentList, result.Value...)
+	}
+
+	scope := "/providers/microsoft.management/managementgroups/" + strings.ToLower(managementGroupId)
+	m.addDeployments(scope, DeploymentScopeTypeManagementGroup, deploymentList)
+}
+
+// addDeployments exports deployments and deployment history counts of one scope
+func (m *MetricsCollectorAzureRmDeployment) addDeployments(scope, scopeType string, deploymentList []*armresources.DeploymentExtended) {
+	infoMetric := m.Collector.GetMetricList("deploymentInfo")
+	timestampMetric := m.Collector.GetMetricList("deploymentTimestamp")
+	durationMetric := m.Collector.GetMetricList("deploymentDuration")
+
+	subscriptionId := ""
+	resourceGroup := ""
+	if azureResource, err := armclient.ParseResourceId(scope); err == nil {
+		subscriptionId = azureResource.Subscription
+		resourceGroup = azureResource.ResourceGroup
+	}
+
+	minTimestamp := time.Now().Add(-Config.Collectors.Deployment.GetMaxAge())
+	stateCount := map[string]float64{}
+
+	for _, deployment := range deploymentList {
+		provisioningState := ""
+		if deployment.Properties != nil && deployment.Properties.ProvisioningState != nil {
+			provisioningState = string(*deployment.Properties.ProvisioningState)
+		}
+		stateCount[provisioningState]++
+
+		if deployment.Properties == nil || deployment.Properties.Timestamp == nil || deployment.Properties.Timestamp.Before(minTimestamp) {
+			continue
+		}
+
+		deploymentId := to.StringLower(deployment.ID)
+
+		mode := ""
+		if deployment.Properties.Mode != nil {
+			mode = string(*deployment.Properties.Mode)
+		}
+
+		infoMetric.AddInfo(prometheus.Labels{
+			"deploymentID":      deploymentId,
+			"deploymentName":    to.String(deployment.Name),
+			"scope":             scope,
+			"scopeType":         scopeType,
+			"subscriptionID":    subscriptionId,
+			"resourceGroup":     resourceGroup,
+			"provisioningState": provisioningState,
+			"mode":              mode,
+			"templateHash":      to.String(deployment.Properties.TemplateHash),
+			"errorCode":         deploymentErrorCode(deployment.Properties.Error),
+		})
+
+		timestampMetric.AddTime(prometheus.Labels{"deploymentID": deploymentId}, *deployment.Properties.Timestamp)
+
+		if duration, ok := parseIso8601Duration(to.String(deployment.Properties.Duration)); ok {
+			durationMetric.Add(prometheus.Labels{"deploymentID": deploymentId}, duration.Seconds())
+		}
+	}
+
+	countMetric := m.Collector.GetMetricList("deploymentCount")
+	for provisioningState, count := range stateCount {
+		countMetric.Add(prometheus.Labels{
+			"scope":             scope,
+			"scopeType":         scopeType,
+			"subscriptionID":    subscriptionId,
+			"resourceGroup":     resourceGroup,
+			"provisioningState": provisioningState,
+		}, count)
+	}
+
+	historyLabels := prometheus.Labels{
+		"scope":          scope,
+		"scopeType":      scopeType,
+		"subscriptionID": subscriptionId,
+		"resourceGroup":  resourceGroup,
+	}
+	m.Collector.GetMetricList("deploymentHistoryCount").Add(historyLabels, float64(len(deploymentList)))
+	m.Collector.GetMetricList("deploymentHistoryUsage").Add(historyLabels, float64(len(deploymentList))/DeploymentHistoryLimit)
+}
+
+// deploymentErrorCode returns the most specific error code (first nested error detail)
+func deploymentErrorCode(deploymentError *armresources.ErrorResponse) string {
+	errorCode := ""
+	for deploymentError != nil {
+		if deploymentError.Code != nil {
+			errorCode = *deploymentError.Code
+		}
+
+		if len(deploymentError.Details) == 0 {
+			break
+		}
+		deploymentError = deploymentError.Details[0]
+	}
+	return errorCode
+}
+
+// parseIso8601Duration parses ISO 8601 durations as used by ResourceManager (eg. PT1M23.456S)
+func parseIso8601Duration(value string) (time.Duration, bool) {
+	match := iso8601DurationRegExp.FindStringSubmatch(value)
+	if match == nil || value == "P" || value == "PT" {
+		return 0, false
+	}
+
+	duration := time.Duration(0)
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+
+		val, err := strconv.ParseFloat(match[i+1], 64)
+		if err != nil {
+			return 0, false
+		}
+		duration += time.Duration(val * float64(unit))
+	}
+
+	return duration, true
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/webdevops/go-common/utils/to"
+)
+
+func TestParseIso8601Duration(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected time.Duration
+		valid    bool
+	}{
+		{value: "PT1M23.456S", expected: time.Minute + 23456*time.Millisecond, valid: true},
+		{value: "P1DT2H", expected: 26 * time.Hour, valid: true},
+		{value: "PT2H3M4S", expected: 2*time.Hour + 3*time.Minute + 4*time.Second, valid: true},
+		{value: "P2D", expected: 48 * time.Hour, valid: true},
+		{value: "PT0.5S", expected: 500 * time.Millisecond, valid: true},
+		{value: "PT", valid: false},
+		{value: "P", valid: false},
+		{value: "", valid: false},
+		{value: "1M23S", valid: false},
+		{value: "PT1.2.3S", valid: false},
+	}
+
+	for _, testCase := range testCases {
+		duration, ok := parseIso8601Duration(testCase.value)
+		if ok != testCase.valid {
+			t.Errorf("%q: expected valid=%v, got %v", testCase.value, testCase.valid, ok)
+			continue
+		}
+		if duration != testCase.expected {
+			t.Errorf("%q: expected %v, got %v", testCase.value, testCase.expected, duration)
+		}
+	}
+}
+
+func TestDeploymentErrorCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		error    *armresources.ErrorResponse
+		expected string
+	}{
+		{name: "no error", error: nil, expected: ""},
+		{name: "single error", error: &armresources.ErrorResponse{Code: to.Ptr("DeploymentFailed")}, expected: "DeploymentFailed"},
+		{
+			name: "nested error details",
+			error: &armresources.ErrorResponse{
+				Code: to.Ptr("DeploymentFailed"),
+				Details: []*armresources.ErrorResponse{
+					{
+						Code: to.Ptr("ResourceDeploymentFailure"),
+						Details: []*armresources.ErrorResponse{
+							{Code: to.Ptr("QuotaExceeded")},
+							{Code: to.Ptr("Conflict")},
+						},
+					},
+					{Code: to.Ptr("BadRequest")},
+				},
+			},
+			expected: "QuotaExceeded",
+		},
+		{
+			name: "nested error detail without code",
+			error: &armresources.ErrorResponse{
+				Code:    to.Ptr("DeploymentFailed"),
+				Details: []*armresources.ErrorResponse{{Message: to.Ptr("unknown")}},
+			},
+			expected: "DeploymentFailed",
+		},
+	}
+
+	for _, testCase := range testCases {
+		if errorCode := deploymentErrorCode(testCase.error); errorCode != testCase.expected {
+			t.Errorf("%v: expected %q, got %q", testCase.name, testCase.expected, errorCode)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"strconv"
+
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/webdevops/go-common/prometheus/collector"
@@ -15,6 +17,8 @@ type MetricsCollectorAzureRmGeneral struct {
 		subscription  *prometheus.GaugeVec
 		resourceGroup *prometheus.GaugeVec
 	}
+
+	managementGroupHierarchy *managementGroupHierarchy
 }
 
 func (m *MetricsCollectorAzureRmGeneral) Setup(collector *collector.Collector) {
@@ -25,22 +29,74 @@ func (m *MetricsCollectorAzureRmGeneral) Setup(collector *collector.Collector) {
 			Name: "azurerm_subscription_info",
 			Help: "Azure ResourceManager subscription",
 		},
-		[]string{
-			"resourceID",
-			"subscriptionID",
-			"subscriptionName",
-			"spendingLimit",
-			"quotaID",
-			"locationPlacementID",
-		},
+		append(
+			[]string{
+				"resourceID",
+				"subscriptionID",
+				"subscriptionName",
+				"spendingLimit",
+				"quotaID",
+				"locationPlacementID",
+			},
+			m.managementGroupPathLabelNames()...,
+		),
 	)
 	m.Collector.RegisterMetricList("subscription", m.prometheus.subscription, true)
+}
+
+// managementGroupPathLabelNames returns management group path label names (if enabled)
+func (m *MetricsCollectorAzureRmGeneral) managementGroupPathLabelNames() (labels []string) {
+	if !Config.Collectors.General.ManagementGroupPath.Enabled {
+		return
+	}
 
+	labels = append(labels, "managementGroupID", "managementGroupPath")
+	for level := 1; level <= Config.Collectors.General.GetManagementGroupLevels(); level++ {
+		labels = append(labels, "managementGroupLevel"+strconv.Itoa(level))
+	}
+	return
+}
+
+// addManagementGroupPathLabels adds management group path labels of subscription (if enabled)
+func (m *MetricsCollectorAzureRmGeneral) addManagementGroupPathLabels(labels prometheus.Labels, subscriptionId string) prometheus.Labels {
+	if !Config.Collectors.General.ManagementGroupPath.Enabled {
+		return labels
+	}
+
+	path := m.managementGroupHierarchy.SubscriptionPath(subscriptionId)
+
+	labels["managementGroupID"] = ""
+	labels["managementGroupPath"] = ""
+	if len(path) > 0 {
+		labels["managementGroupID"] = path[len(path)-1].ID
+		labels["managementGroupPath"] = managementGroupPathString(path)
+	}
+
+	// level 0 is the root management group
+	for level := 1; level <= Config.Collectors.General.GetManagementGroupLevels(); level++ {
+		labelName := "managementGroupLevel" + strconv.Itoa(level)
+		labels[labelName] = ""
+		if level < len(path) {
+			labels[labelName] = path[level].ID
+		}
+	}
+
+	return labels
 }
 
 func (m *MetricsCollectorAzureRmGeneral) Reset() {}
 
 func (m *MetricsCollectorAzureRmGeneral) Collect(callback chan<- func()) {
+	if Config.Collectors.General.ManagementGroupPath.Enabled {
+		hierarchy, err := cachedManagementGroupHierarchy(m.Context())
+		if err != nil {
+			// subscription metrics are still exported, with empty management group labels
+			m.Logger().Warnf(`unable to fetch management group hierarchy: %v`, err.Error())
+			hierarchy = newManagementGroupHierarchy(Config.Collectors.ManagementGroup.GetRoot(*Opts.Azure.Tenant))
+		}
+		m.managementGroupHierarchy = hierarchy
+	}
+
 	err := AzureSubscriptionsIterator.ForEachAsync(m.Logger(), func(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
 		m.collectSubscription(subscription, logger, callback)
 	})
@@ -58,12 +114,15 @@ func (m *MetricsCollectorAzureRmGeneral) collectSubscription(subscription *armsu
 		spendingLimit = string(*subscription.SubscriptionPolicies.SpendingLimit)
 	}
 
-	subscriptionMetric.AddInfo(prometheus.Labels{
+	labels := prometheus.Labels{
 		"resourceID":          to.StringLower(subscription.ID),
 		"subscriptionID":      to.StringLower(subscription.SubscriptionID),
 		"subscriptionName":    to.String(subscription.DisplayName),
 		"spendingLimit":       spendingLimit,
 		"quotaID":             to.StringLower(subscription.SubscriptionPolicies.QuotaID),
 		"locationPlacementID": to.StringLower(subscription.SubscriptionPolicies.LocationPlacementID),
-	})
+	}
+	labels = m.addManagementGroupPathLabels(labels, to.StringLower(subscription.SubscriptionID))
+
+	subscriptionMetric.AddInfo(labels)
 }
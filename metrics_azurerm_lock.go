@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/azuresdk/armclient"
+	"github.com/webdevops/go-common/prometheus/collector"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+)
+
+const (
+	LockApiVersion = "2016-09-01"
+
+	LockLevelCanNotDelete = "cannotdelete"
+	LockLevelReadOnly     = "readonly"
+)
+
+var (
+	lockScopeRegExp = regexp.MustCompile(`(?i)^(.*)/providers/Microsoft\.Authorization/locks/[^/]+$`)
+)
+
+type (
+	managementLockResource struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Properties struct {
+			Level  string `json:"level"`
+			Notes  string `json:"notes"`
+			Owners []struct {
+				ApplicationID string `json:"applicationId"`
+			} `json:"owners"`
+		} `json:"properties"`
+	}
+)
+
+type MetricsCollectorAzureRmLock struct {
+	collector.Processor
+
+	prometheus struct {
+		lockInfo                 *prometheus.GaugeVec
+		resourceGroupLockMissing *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorAzureRmLock) Setup(collector *collector.Collector) {
+	m.Processor.Setup(collector)
+
+	m.prometheus.lockInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_lock_info",
+			Help: "Azure ResourceManager management lock information",
+		},
+		[]string{
+			"lockID",
+			"lockName",
+			"subscriptionID",
+			"resourceGroup",
+			"scope",
+			"scopeType",
+			"level",
+			"notes",
+			"owners",
+		},
+	)
+	m.Collector.RegisterMetricList("lockInfo", m.prometheus.lockInfo, true)
+
+	if Config.Collectors.Lock.RequiredLock != nil {
+		m.prometheus.resourceGroupLockMissing = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_resourcegroup_lock_missing",
+				Help: "Azure ResourceManager resourcegroup (selected by tags) without required lock (1: missing, 0: locked)",
+			},
+			AzureResourceGroupTagManager.AddToPrometheusLabels(
+				[]string{
+					"resourceID",
+					"subscriptionID",
+					"resourceGroup",
+					"requiredLevel",
+				},
+			),
+		)
+		m.Collector.RegisterMetricList("resourceGroupLockMissing", m.prometheus.resourceGroupLockMissing, true)
+	}
+}
+
+func (m *MetricsCollectorAzureRmLock) Reset() {}
+
+func (m *MetricsCollectorAzureRmLock) Collect(callback chan<- func()) {
+	err := AzureSubscriptionsIterator.ForEachAsync(m.Logger(), func(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+		m.collectLocks(subscription, logger)
+	})
+	if err != nil {
+		m.Logger().Panic(err)
+	}
+}
+
+func (m *MetricsCollectorAzureRmLock) collectLocks(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	lockInfoMetric := m.Collector.GetMetricList("lockInfo")
+
+	// lock levels per scope (subscription, resourcegroup or resource)
+	scopeLockLevels := map[string][]string{}
+
+	urlPath := "/subscriptions/" + *subscription.SubscriptionID + "/providers/Microsoft.Authorization/locks"
+	// an empty list would be reported as missing locks, fail the subscription on errors
+	locks, err := listArmApiResources(m.Context(), urlPath, url.Values{"api-version": {LockApiVersion}})
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	for _, row := range locks {
+		lock := managementLockResource{}
+		if err := json.Unmarshal(row, &lock); err != nil {
+			logger.Panic(err)
+		}
+
+		scope := ""
+		if match := lockScopeRegExp.FindStringSubmatch(lock.ID); len(match) == 2 {
+			scope = strings.ToLower(match[1])
+		}
+
+		azureResource, err := armclient.ParseResourceId(scope)
+		if err != nil {
+			logger.Warnf(`unable to parse lock scope "%v": %v`, scope, err.Error())
+			continue
+		}
+
+		scopeType := "resource"
+		if azureResource.ResourceName == "" {
+			scopeType = "resourceGroup"
+			if azureResource.ResourceGroup == "" {
+				scopeType = "subscription"
+			}
+		}
+
+		owners := []string{}
+		for _, owner := range lock.Properties.Owners {
+			owners = append(owners, owner.ApplicationID)
+		}
+
+		scopeLockLevels[scope] = append(scopeLockLevels[scope], strings.ToLower(lock.Properties.Level))
+
+		lockInfoMetric.AddInfo(prometheus.Labels{
+			"lockID":         stringToStringLower(lock.ID),
+			"lockName":       lock.Name,
+			"subscriptionID": azureResource.Subscription,
+			"resourceGroup":  azureResource.ResourceGroup,
+			"scope":          scope,
+			"scopeType":      scopeType,
+			"level":          lock.Properties.Level,
+			"notes":          lock.Properties.Notes,
+			"owners":         strings.Join(owners, ","),
+		})
+	}
+
+	if Config.Collectors.Lock.RequiredLock != nil {
+		m.collectResourceGroupLockMissing(subscription, scopeLockLevels, logger)
+	}
+}
+
+// collectResourceGroupLockMissing checks if resourcegroups selected by tags are locked (directly or via subscription lock)
+func (m *MetricsCollectorAzureRmLock) collectResourceGroupLockMissing(subscription *armsubscriptions.Subscription, scopeLockLevels map[string][]string, logger *zap.SugaredLogger) {
+	lockMissingMetric := m.Collector.GetMetricList("resourceGroupLockMissing")
+	requiredLock := Config.Collectors.Lock.RequiredLock
+	requiredLevel := strings.ToLower(requiredLock.GetLevel())
+
+	resourceGroupList, err := AzureClient.ListResourceGroups(m.Context(), *subscription.SubscriptionID)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	subscriptionScope := "/subscriptions/" + to.StringLower(subscription.SubscriptionID)
+
+	for _, resourceGroup := range resourceGroupList {
+		if !requiredLock.MatchesTags(newResourceTagMap(resourceGroup.Tags)) {
+			continue
+		}
+
+		resourceId := to.StringLower(resourceGroup.ID)
+		azureResource, _ := armclient.ParseResourceId(resourceId)
+
+		locked := false
+		for _, scope := range []string{resourceId, subscriptionScope} {
+			for _, level := range scopeLockLevels[scope] {
+				// ReadOnly lock also prevents deletion
+				if level == requiredLevel || level == LockLevelReadOnly {
+					locked = true
+				}
+			}
+		}
+
+		labels := prometheus.Labels{
+			"resourceID":     resourceId,
+			"subscriptionID": azureResource.Subscription,
+			"resourceGroup":  azureResource.ResourceGroup,
+			"requiredLevel":  requiredLock.GetLevel(),
+		}
+		labels = AzureResourceGroupTagManager.AddResourceTagsToPrometheusLabels(m.Context(), labels, resourceId)
+
+		lockMissingMetric.AddBool(labels, !locked)
+	}
+}
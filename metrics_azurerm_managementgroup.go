@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/prometheus/collector"
+	"github.com/webdevops/go-common/utils/to"
+)
+
+const (
+	ManagementGroupResourceType = "microsoft.management/managementgroups"
+	ManagementGroupIdPrefix     = "/providers/microsoft.management/managementgroups/"
+)
+
+type (
+	// managementGroupHierarchy contains management groups and subscriptions below the root management group
+	managementGroupHierarchy struct {
+		root string
+
+		// management groups (key: management group ID)
+		groups map[string]*managementGroupNode
+
+		// parent management group ID of subscriptions (key: subscription ID)
+		subscriptions map[string]string
+	}
+
+	managementGroupNode struct {
+		ID          string
+		DisplayName string
+		ParentID    string
+	}
+)
+
+var (
+	// management group hierarchy shared by managementGroup and general collector
+	managementGroupHierarchyCache struct {
+		lock      sync.Mutex
+		hierarchy *managementGroupHierarchy
+		expiry    time.Time
+	}
+)
+
+type MetricsCollectorAzureRmManagementGroup struct {
+	collector.Processor
+
+	prometheus struct {
+		managementGroup             *prometheus.GaugeVec
+		managementGroupSubscription *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorAzureRmManagementGroup) Setup(collector *collector.Collector) {
+	m.Processor.Setup(collector)
+
+	m.prometheus.managementGroup = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_managementgroup_info",
+			Help: "Azure ResourceManager management group",
+		},
+		[]string{
+			"id",
+			"displayName",
+			"parentID",
+			"depth",
+			"path",
+		},
+	)
+	m.Collector.RegisterMetricList("managementGroup", m.prometheus.managementGroup, true)
+
+	m.prometheus.managementGroupSubscription = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_managementgroup_subscription_info",
+			Help: "Azure ResourceManager subscription to management group mapping",
+		},
+		[]string{
+			"subscriptionID",
+			"managementGroupID",
+			"path",
+		},
+	)
+	m.Collector.RegisterMetricList("managementGroupSubscription", m.prometheus.managementGroupSubscription, true)
+}
+
+func (m *MetricsCollectorAzureRmManagementGroup) Reset() {}
+
+func (m *MetricsCollectorAzureRmManagementGroup) Collect(callback chan<- func()) {
+	managementGroupMetric := m.Collector.GetMetricList("managementGroup")
+	subscriptionMetric := m.Collector.GetMetricList("managementGroupSubscription")
+
+	hierarchy, err := cachedManagementGroupHierarchy(m.Context())
+	if err != nil {
+		m.Logger().Panic(err)
+	}
+
+	for _, group := range hierarchy.groups {
+		path := hierarchy.Path(group.ID)
+		managementGroupMetric.AddInfo(prometheus.Labels{
+			"id":          group.ID,
+			"displayName": group.DisplayName,
+			"parentID":    group.ParentID,
+			"depth":       strconv.Itoa(len(path) - 1),
+			"path":        managementGroupPathString(path),
+		})
+	}
+
+	// only subscriptions visible to the exporter (subscription filter)
+	subscriptionList, err := AzureSubscriptionsIterator.ListSubscriptions()
+	if err != nil {
+		m.Logger().Panic(err)
+	}
+
+	for _, subscription := range subscriptionList {
+		subscriptionId := to.StringLower(subscription.SubscriptionID)
+		managementGroupId, exists := hierarchy.subscriptions[subscriptionId]
+		if !exists {
+			continue
+		}
+
+		subscriptionMetric.AddInfo(prometheus.Labels{
+			"subscriptionID":    subscriptionId,
+			"managementGroupID": managementGroupId,
+			"path":              managementGroupPathString(hierarchy.Path(managementGroupId)),
+		})
+	}
+}
+
+// cachedManagementGroupHierarchy returns management group hierarchy of the configured root (collectors.managementGroup.root),
+// fetched once per scrapeTime of managementGroup collector (or general collector if disabled)
+func cachedManagementGroupHierarchy(ctx context.Context) (*managementGroupHierarchy, error) {
+	managementGroupHierarchyCache.lock.Lock()
+	defer managementGroupHierarchyCache.lock.Unlock()
+
+	if managementGroupHierarchyCache.hierarchy != nil && time.Now().Before(managementGroupHierarchyCache.expiry) {
+		return managementGroupHierarchyCache.hierarchy, nil
+	}
+
+	hierarchy, err := fetchManagementGroupHierarchy(ctx, Config.Collectors.ManagementGroup.GetRoot(*Opts.Azure.Tenant))
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := *Config.Collectors.General.ScrapeTime
+	if Config.Collectors.ManagementGroup.IsEnabled() {
+		ttl = *Config.Collectors.ManagementGroup.ScrapeTime
+	}
+
+	// expire slightly before next run to refresh once per run
+	// (margin limited to half of ttl so short scrapeTimes still cache)
+	ttlMargin := time.Minute
+	if ttlMargin > ttl/2 {
+		ttlMargin = ttl / 2
+	}
+
+	managementGroupHierarchyCache.hierarchy = hierarchy
+	managementGroupHierarchyCache.expiry = time.Now().Add(ttl - ttlMargin)
+
+	return hierarchy, nil
+}
+
+// fetchManagementGroupHierarchy fetches root management group and all its descendants
+func fetchManagementGroupHierarchy(ctx context.Context, root string) (*managementGroupHierarchy, error) {
+	hierarchy := newManagementGroupHierarchy(root)
+
+	client, err := armmanagementgroups.NewClient(AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	rootGroup, err := client.Get(ctx, root, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rootNode := &managementGroupNode{ID: hierarchy.root}
+	if rootGroup.Properties != nil {
+		rootNode.DisplayName = to.String(rootGroup.Properties.DisplayName)
+	}
+	hierarchy.groups[rootNode.ID] = rootNode
+
+	pager := client.NewGetDescendantsPager(root, nil)
+	for pager.More() {
+		result, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, descendant := range result.Value {
+			parentId := ""
+			displayName := ""
+			if descendant.Properties != nil {
+				displayName = to.String(descendant.Properties.DisplayName)
+				if descendant.Properties.Parent != nil {
+					parentId = managementGroupIdFromResourceId(to.String(descendant.Properties.Parent.ID))
+				}
+			}
+
+			if strings.EqualFold(strings.TrimPrefix(to.String(descendant.Type), "/"), ManagementGroupResourceType) {
+				node := &managementGroupNode{
+					ID:          to.StringLower(descendant.Name),
+					DisplayName: displayName,
+					ParentID:    parentId,
+				}
+				hierarchy.groups[node.ID] = node
+			} else {
+				hierarchy.subscriptions[to.StringLower(descendant.Name)] = parentId
+			}
+		}
+	}
+
+	return hierarchy, nil
+}
+
+// newManagementGroupHierarchy creates an empty management group hierarchy
+func newManagementGroupHierarchy(root string) *managementGroupHierarchy {
+	return &managementGroupHierarchy{
+		root:          strings.ToLower(root),
+		groups:        map[string]*managementGroupNode{},
+		subscriptions: map[string]string{},
+	}
+}
+
+// Path returns management groups from root to the management group
+func (h *managementGroupHierarchy) Path(managementGroupId string) (path []*managementGroupNode) {
+	visited := map[string]bool{}
+	for managementGroupId != "" && !visited[managementGroupId] {
+		node, exists := h.groups[managementGroupId]
+		if !exists {
+			break
+		}
+		visited[managementGroupId] = true
+		path = append([]*managementGroupNode{node}, path...)
+
+		if managementGroupId == h.root {
+			break
+		}
+		managementGroupId = node.ParentID
+	}
+	return
+}
+
+// SubscriptionPath returns management groups from root to the parent management group of the subscription
+func (h *managementGroupHierarchy) SubscriptionPath(subscriptionId string) []*managementGroupNode {
+	if managementGroupId, exists := h.subscriptions[strings.ToLower(subscriptionId)]; exists {
+		return h.Path(managementGroupId)
+	}
+	return nil
+}
+
+// managementGroupPathString returns path as string (eg. /root/businessunit/team)
+func managementGroupPathString(path []*managementGroupNode) string {
+	parts := []string{}
+	for _, node := range path {
+		parts = append(parts, node.ID)
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// managementGroupIdFromResourceId returns management group ID from resource ID (/providers/Microsoft.Management/managementGroups/xxx)
+func managementGroupIdFromResourceId(resourceId string) string {
+	resourceId = strings.ToLower(resourceId)
+	if strings.HasPrefix(resourceId, ManagementGroupIdPrefix) {
+		return strings.TrimPrefix(resourceId, ManagementGroupIdPrefix)
+	}
+	return ""
+}
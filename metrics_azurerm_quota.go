@@ -29,6 +29,8 @@ type MetricsCollectorAzureRmQuota struct {
 		quotaLimit   *prometheus.GaugeVec
 		quotaUsage   *prometheus.GaugeVec
 	}
+
+	headroomSkuCache quotaHeadroomSkuCache
 }
 
 func (m *MetricsCollectorAzureRmQuota) Setup(collector *collector.Collector) {
@@ -95,6 +97,18 @@ func (m *MetricsCollectorAzureRmQuota) Setup(collector *collector.Collector) {
 	m.Collector.RegisterMetricList("quotaCurrent", m.prometheus.quotaCurrent, true)
 	m.Collector.RegisterMetricList("quotaLimit", m.prometheus.quotaLimit, true)
 	m.Collector.RegisterMetricList("quotaUsage", m.prometheus.quotaUsage, true)
+
+	if Config.Collectors.Quota.Requests {
+		m.setupQuotaApiRequests()
+	}
+
+	if Config.Collectors.Quota.Headroom != nil {
+		m.setupQuotaHeadroom()
+	}
+
+	if Config.Collectors.Quota.Forecast != nil {
+		m.setupQuotaForecast()
+	}
 }
 
 func (m *MetricsCollectorAzureRmQuota) Reset() {}
@@ -103,38 +117,50 @@ func (m *MetricsCollectorAzureRmQuota) Collect(callback chan<- func()) {
 	err := AzureSubscriptionsIterator.ForEachAsync(m.Logger(), func(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
 		m.collectAuthorizationUsage(subscription, logger, callback)
 
-		// if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.Capacity"); registered {
-		// 	m.collectQuotaUsage(subscription, logger, callback)
-		// } else if err != nil {
-		// 	logger.Error(err.Error())
-		// }
+		locations := m.discoverQuotaLocations(subscription, logger)
 
-		if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.Compute"); registered {
-			m.collectAzureComputeUsage(subscription, logger, callback)
-		} else if err != nil {
-			logger.Error(err.Error())
+		// free compute quota for headroom calculation (builtin compute usage or Microsoft.Quota API)
+		var computeQuotaFree quotaFreeList
+
+		if len(Config.Collectors.Quota.Providers) > 0 {
+			if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.Quota"); registered {
+				for _, provider := range Config.Collectors.Quota.Providers {
+					quotaFree := m.collectQuotaApiUsage(subscription, provider, locations, logger.With(zap.String("provider", provider.Provider)), callback)
+					if strings.EqualFold(provider.Provider, "Microsoft.Compute") {
+						computeQuotaFree = quotaFree
+					}
+				}
+			} else if err != nil {
+				logger.Error(err.Error())
+			} else {
+				logger.Warn(`resource provider "Microsoft.Quota" is not registered, skipping generic quota collection`)
+			}
 		}
 
-		if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.Network"); registered {
-			m.collectAzureNetworkUsage(subscription, logger, callback)
+		if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.Compute"); registered && !Config.Collectors.Quota.HasProvider("Microsoft.Compute") {
+			computeQuotaFree = m.collectAzureComputeUsage(subscription, locations, logger, callback)
 		} else if err != nil {
 			logger.Error(err.Error())
 		}
 
-		if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.Storage"); registered {
-			m.collectAzureStorageUsage(subscription, logger, callback)
+		if Config.Collectors.Quota.Headroom != nil && computeQuotaFree != nil {
+			m.collectQuotaHeadroom(subscription, computeQuotaFree, logger)
+		}
+
+		if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.Network"); registered && !Config.Collectors.Quota.HasProvider("Microsoft.Network") {
+			m.collectAzureNetworkUsage(subscription, locations, logger, callback)
 		} else if err != nil {
 			logger.Error(err.Error())
 		}
 
-		if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.Storage"); registered {
-			m.collectAzureStorageUsage(subscription, logger, callback)
+		if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.Storage"); registered && !Config.Collectors.Quota.HasProvider("Microsoft.Storage") {
+			m.collectAzureStorageUsage(subscription, locations, logger, callback)
 		} else if err != nil {
 			logger.Error(err.Error())
 		}
 
-		if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.MachineLearningServices"); registered {
-			m.collectAzureMachineLearningUsage(subscription, logger, callback)
+		if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.MachineLearningServices"); registered && !Config.Collectors.Quota.HasProvider("Microsoft.MachineLearningServices") {
+			m.collectAzureMachineLearningUsage(subscription, locations, logger, callback)
 		} else if err != nil {
 			logger.Error(err.Error())
 		}
@@ -142,6 +168,10 @@ func (m *MetricsCollectorAzureRmQuota) Collect(callback chan<- func()) {
 	if err != nil {
 		m.Logger().Panic(err)
 	}
+
+	if Config.Collectors.Quota.Forecast != nil {
+		m.collectQuotaForecast()
+	}
 }
 
 // collectAzureComputeUsage collects compute usages
@@ -222,69 +252,10 @@ func (m *MetricsCollectorAzureRmQuota) collectAuthorizationUsage(subscription *a
 	}
 }
 
-// collectQuotaUsage collect generic quota usages
-// func (m *MetricsCollectorAzureRmQuota) collectQuotaUsage(subscription *armsubscriptions.Subscription, provider string, logger *zap.SugaredLogger, callback chan<- func()) {
-// 	client, err := armquota.NewUsagesClient(AzureClient.GetCred(), AzureClient.NewArmClientOptions())
-// 	if err != nil {
-// 		logger.Panic(err)
-// 	}
-//
-// 	quotaMetric := m.Collector.GetMetricList("quota")
-// 	quotaCurrentMetric := m.Collector.GetMetricList("quotaCurrent")
-// 	quotaLimitMetric := m.Collector.GetMetricList("quotaLimit")
-// 	quotaUsageMetric := m.Collector.GetMetricList("quotaUsage")
-//
-// 	for _, location := range Opts.Azure.Location {
-// 		scope := "/subscriptions/{subscriptionId}/providers/{provider}/locations/{location}"
-// 		scope = strings.ReplaceAll(scope, "{subscriptionId}", url.PathEscape(*subscription.SubscriptionID))
-// 		scope = strings.ReplaceAll(scope, "{provider}", url.PathEscape(provider))
-// 		scope = strings.ReplaceAll(scope, "{location}", url.PathEscape(location))
-//
-// 		pager := client.NewListPager(scope, nil)
-// 		for pager.More() {
-// 			result, err := pager.NextPage(m.Context())
-// 			if err != nil {
-// 				logger.Panic(err)
-// 			}
-//
-// 			if result.Value == nil {
-// 				continue
-// 			}
-//
-// 			for _, resourceUsage := range result.Value {
-// 				quotaName := to.String(resourceUsage.Properties.Name.Value)
-// 				quotaNameLocalized := to.String(resourceUsage.Properties.Name.LocalizedValue)
-// 				currentValue := float64(to.Number(resourceUsage.Properties.Usages.Value))
-// 				limitValue := float64(to.Number(resourceUsage.Properties.Usages.Limit))
-//
-// 				infoLabels := prometheus.Labels{
-// 					"subscriptionID": to.StringLower(subscription.SubscriptionID),
-// 					"location":       strings.ToLower(location),
-// 					"scope":          provider,
-// 					"quota":          quotaName,
-// 					"quotaName":      quotaNameLocalized,
-// 				}
-//
-// 				labels := prometheus.Labels{
-// 					"subscriptionID": to.StringLower(subscription.SubscriptionID),
-// 					"location":       strings.ToLower(location),
-// 					"scope":          provider,
-// 					"quota":          quotaName,
-// 				}
-//
-// 				quotaMetric.Add(infoLabels, 1)
-// 				quotaCurrentMetric.Add(labels, currentValue)
-// 				quotaLimitMetric.Add(labels, limitValue)
-// 				if limitValue != 0 {
-// 					quotaUsageMetric.Add(labels, currentValue/limitValue)
-// 				}
-// 			}
-// 		}
-// 	}
-// }
+// collectAzureComputeUsage collects compute usages and returns free quota per location
+func (m *MetricsCollectorAzureRmQuota) collectAzureComputeUsage(subscription *armsubscriptions.Subscription, locations []string, logger *zap.SugaredLogger, callback chan<- func()) quotaFreeList {
+	quotaFree := quotaFreeList{}
 
-// collectAzureComputeUsage collects compute usages
-func (m *MetricsCollectorAzureRmQuota) collectAzureComputeUsage(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger, callback chan<- func()) {
 	client, err := armcompute.NewUsageClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
 	if err != nil {
 		logger.Panic(err)
@@ -295,7 +266,7 @@ func (m *MetricsCollectorAzureRmQuota) collectAzureComputeUsage(subscription *ar
 	quotaLimitMetric := m.Collector.GetMetricList("quotaLimit")
 	quotaUsageMetric := m.Collector.GetMetricList("quotaUsage")
 
-	for _, location := range Config.Azure.Locations {
+	for _, location := range m.quotaProviderLocations(subscription, "Microsoft.Compute", locations, logger) {
 		pager := client.NewListPager(location, nil)
 
 		for pager.More() {
@@ -337,13 +308,17 @@ func (m *MetricsCollectorAzureRmQuota) collectAzureComputeUsage(subscription *ar
 				if limitValue != 0 {
 					quotaUsageMetric.Add(labels, currentValue/limitValue)
 				}
+
+				quotaFree.Add(location, quotaName, limitValue, currentValue)
 			}
 		}
 	}
+
+	return quotaFree
 }
 
 // collectAzureComputeUsage collects network usages
-func (m *MetricsCollectorAzureRmQuota) collectAzureNetworkUsage(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger, callback chan<- func()) {
+func (m *MetricsCollectorAzureRmQuota) collectAzureNetworkUsage(subscription *armsubscriptions.Subscription, locations []string, logger *zap.SugaredLogger, callback chan<- func()) {
 	client, err := armnetwork.NewUsagesClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
 	if err != nil {
 		logger.Panic(err)
@@ -354,7 +329,7 @@ func (m *MetricsCollectorAzureRmQuota) collectAzureNetworkUsage(subscription *ar
 	quotaLimitMetric := m.Collector.GetMetricList("quotaLimit")
 	quotaUsageMetric := m.Collector.GetMetricList("quotaUsage")
 
-	for _, location := range Config.Azure.Locations {
+	for _, location := range m.quotaProviderLocations(subscription, "Microsoft.Network", locations, logger) {
 		pager := client.NewListPager(location, nil)
 
 		for pager.More() {
@@ -402,7 +377,7 @@ func (m *MetricsCollectorAzureRmQuota) collectAzureNetworkUsage(subscription *ar
 }
 
 // collectAzureComputeUsage collects storage usages
-func (m *MetricsCollectorAzureRmQuota) collectAzureStorageUsage(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger, callback chan<- func()) {
+func (m *MetricsCollectorAzureRmQuota) collectAzureStorageUsage(subscription *armsubscriptions.Subscription, locations []string, logger *zap.SugaredLogger, callback chan<- func()) {
 	client, err := armstorage.NewUsagesClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
 	if err != nil {
 		logger.Panic(err)
@@ -413,7 +388,7 @@ func (m *MetricsCollectorAzureRmQuota) collectAzureStorageUsage(subscription *ar
 	quotaLimitMetric := m.Collector.GetMetricList("quotaLimit")
 	quotaUsageMetric := m.Collector.GetMetricList("quotaUsage")
 
-	for _, location := range Config.Azure.Locations {
+	for _, location := range m.quotaProviderLocations(subscription, "Microsoft.Storage", locations, logger) {
 		pager := client.NewListByLocationPager(location, nil)
 
 		for pager.More() {
@@ -461,7 +436,7 @@ func (m *MetricsCollectorAzureRmQuota) collectAzureStorageUsage(subscription *ar
 }
 
 // collectAzureComputeUsage collects machinelearning usages
-func (m *MetricsCollectorAzureRmQuota) collectAzureMachineLearningUsage(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger, callback chan<- func()) {
+func (m *MetricsCollectorAzureRmQuota) collectAzureMachineLearningUsage(subscription *armsubscriptions.Subscription, locations []string, logger *zap.SugaredLogger, callback chan<- func()) {
 	client, err := armmachinelearning.NewUsagesClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
 	if err != nil {
 		logger.Panic(err)
@@ -472,7 +447,7 @@ func (m *MetricsCollectorAzureRmQuota) collectAzureMachineLearningUsage(subscrip
 	quotaLimitMetric := m.Collector.GetMetricList("quotaLimit")
 	quotaUsageMetric := m.Collector.GetMetricList("quotaUsage")
 
-	for _, location := range Config.Azure.Locations {
+	for _, location := range m.quotaProviderLocations(subscription, "Microsoft.MachineLearningServices", locations, logger) {
 		pager := client.NewListPager(location, nil)
 
 		for pager.More() {
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+
+	"github.com/webdevops/azure-resourcemanager-exporter/config"
+)
+
+const (
+	QuotaApiVersion = "2023-02-01"
+)
+
+type (
+	quotaApiResourceName struct {
+		Value          string `json:"value"`
+		LocalizedValue string `json:"localizedValue"`
+	}
+
+	quotaApiUsage struct {
+		Name       string `json:"name"`
+		Properties struct {
+			Name   quotaApiResourceName `json:"name"`
+			Usages struct {
+				Value *float64 `json:"value"`
+			} `json:"usages"`
+		} `json:"properties"`
+	}
+
+	quotaApiRequest struct {
+		Name       string `json:"name"`
+		Properties struct {
+			ProvisioningState string                `json:"provisioningState"`
+			Error             *quotaApiRequestError `json:"error"`
+			RequestSubmitTime *time.Time            `json:"requestSubmitTime"`
+			Value             []struct {
+				Name              quotaApiResourceName  `json:"name"`
+				ProvisioningState string                `json:"provisioningState"`
+				Error             *quotaApiRequestError `json:"error"`
+				Limit             struct {
+					Value *float64 `json:"value"`
+				} `json:"limit"`
+			} `json:"value"`
+		} `json:"properties"`
+	}
+
+	quotaApiRequestError struct {
+		Code string `json:"code"`
+	}
+
+	quotaApiQuota struct {
+		Name       string `json:"name"`
+		Properties struct {
+			Name  quotaApiResourceName `json:"name"`
+			Limit struct {
+				LimitObjectType string   `json:"limitObjectType"`
+				Value           *float64 `json:"value"`
+			} `json:"limit"`
+		} `json:"properties"`
+	}
+)
+
+// collectQuotaApiUsage collects usages and limits of resource provider using generic Microsoft.Quota API and returns free quota per location
+func (m *MetricsCollectorAzureRmQuota) collectQuotaApiUsage(subscription *armsubscriptions.Subscription, provider config.CollectorQuotaProvider, subscriptionLocations []string, logger *zap.SugaredLogger, callback chan<- func()) quotaFreeList {
+	quotaFree := quotaFreeList{}
+
+	quotaMetric := m.Collector.GetMetricList("quota")
+	quotaCurrentMetric := m.Collector.GetMetricList("quotaCurrent")
+	quotaLimitMetric := m.Collector.GetMetricList("quotaLimit")
+	quotaUsageMetric := m.Collector.GetMetricList("quotaUsage")
+
+	locations := provider.Locations
+	if len(locations) == 0 {
+		locations = m.quotaProviderLocations(subscription, provider.Provider, subscriptionLocations, logger)
+	}
+
+	for _, location := range locations {
+		scope := provider.GetScopeTemplate()
+		scope = strings.ReplaceAll(scope, "{subscriptionId}", url.PathEscape(*subscription.SubscriptionID))
+		scope = strings.ReplaceAll(scope, "{provider}", url.PathEscape(provider.Provider))
+		scope = strings.ReplaceAll(scope, "{location}", url.PathEscape(location))
+
+		locationLogger := logger.With(zap.String("quotaScope", scope))
+
+		// fetch limits and usages, skip location on errors (eg. provider not available in location)
+		quotaRows, err := listArmApiResources(m.Context(), scope+"/providers/Microsoft.Quota/quotas", url.Values{"api-version": {QuotaApiVersion}})
+		if err != nil {
+			locationLogger.Warn(err)
+			continue
+		}
+
+		usageRows, err := listArmApiResources(m.Context(), scope+"/providers/Microsoft.Quota/usages", url.Values{"api-version": {QuotaApiVersion}})
+		if err != nil {
+			locationLogger.Warn(err)
+			continue
+		}
+
+		limitList := map[string]*float64{}
+		for _, row := range quotaRows {
+			quota := quotaApiQuota{}
+			if err := json.Unmarshal(row, &quota); err != nil {
+				locationLogger.Panic(err)
+			}
+
+			if quota.Properties.Limit.Value != nil {
+				limitList[strings.ToLower(quota.Properties.Name.Value)] = quota.Properties.Limit.Value
+			}
+		}
+
+		for _, row := range usageRows {
+			usage := quotaApiUsage{}
+			if err := json.Unmarshal(row, &usage); err != nil {
+				locationLogger.Panic(err)
+			}
+
+			quotaName := usage.Properties.Name.Value
+			if quotaName == "" {
+				quotaName = usage.Name
+			}
+			quotaNameLocalized := usage.Properties.Name.LocalizedValue
+			currentValue := to.Float64(usage.Properties.Usages.Value)
+
+			infoLabels := prometheus.Labels{
+				"subscriptionID": to.StringLower(subscription.SubscriptionID),
+				"location":       strings.ToLower(location),
+				"provider":       strings.ToLower(provider.Provider),
+				"scope":          provider.GetName(),
+				"quota":          quotaName,
+				"quotaName":      quotaNameLocalized,
+			}
+
+			labels := prometheus.Labels{
+				"subscriptionID": to.StringLower(subscription.SubscriptionID),
+				"location":       strings.ToLower(location),
+				"provider":       strings.ToLower(provider.Provider),
+				"scope":          provider.GetName(),
+				"quota":          quotaName,
+			}
+
+			quotaMetric.Add(infoLabels, 1)
+			quotaCurrentMetric.Add(labels, currentValue)
+			if limitValue, exists := limitList[strings.ToLower(quotaName)]; exists {
+				quotaLimitMetric.Add(labels, *limitValue)
+				if *limitValue != 0 {
+					quotaUsageMetric.Add(labels, currentValue / *limitValue)
+				}
+				quotaFree.Add(location, quotaName, *limitValue, currentValue)
+			}
+		}
+
+		if Config.Collectors.Quota.Requests {
+			m.collectQuotaApiRequests(subscription, provider, location, scope, locationLogger)
+		}
+	}
+
+	return quotaFree
+}
+
+// collectQuotaApiRequests collects quota increase requests of quota scope
+// only pending requests and finished requests within requestsMaxAge are exported
+func (m *MetricsCollectorAzureRmQuota) collectQuotaApiRequests(subscription *armsubscriptions.Subscription, provider config.CollectorQuotaProvider, location, scope string, logger *zap.SugaredLogger) {
+	quotaRequestInfoMetric := m.Collector.GetMetricList("quotaRequestInfo")
+	quotaRequestLimitMetric := m.Collector.GetMetricList("quotaRequestLimit")
+	quotaRequestSubmitMetric := m.Collector.GetMetricList("quotaRequestSubmit")
+
+	minSubmitTime := time.Now().Add(-Config.Collectors.Quota.GetRequestsMaxAge())
+
+	requestRows, err := listArmApiResources(m.Context(), scope+"/providers/Microsoft.Quota/quotaRequests", url.Values{"api-version": {QuotaApiVersion}})
+	if err != nil {
+		logger.Warn(err)
+		return
+	}
+
+	for _, row := range requestRows {
+		request := quotaApiRequest{}
+		if err := json.Unmarshal(row, &request); err != nil {
+			logger.Panic(err)
+		}
+
+		if quotaApiRequestIsFinished(request.Properties.ProvisioningState) {
+			if request.Properties.RequestSubmitTime == nil || request.Properties.RequestSubmitTime.Before(minSubmitTime) {
+				continue
+			}
+		}
+
+		for _, subRequest := range request.Properties.Value {
+			status := subRequest.ProvisioningState
+			if status == "" {
+				status = request.Properties.ProvisioningState
+			}
+
+			errorCode := ""
+			if subRequest.Error != nil {
+				errorCode = subRequest.Error.Code
+			} else if request.Properties.Error != nil {
+				errorCode = request.Properties.Error.Code
+			}
+
+			labels := prometheus.Labels{
+				"subscriptionID": to.StringLower(subscription.SubscriptionID),
+				"location":       strings.ToLower(location),
+				"provider":       strings.ToLower(provider.Provider),
+				"scope":          provider.GetName(),
+				"quota":          subRequest.Name.Value,
+				"requestID":      request.Name,
+			}
+
+			infoLabels := prometheus.Labels{
+				"subscriptionID": to.StringLower(subscription.SubscriptionID),
+				"location":       strings.ToLower(location),
+				"provider":       strings.ToLower(provider.Provider),
+				"scope":          provider.GetName(),
+				"quota":          subRequest.Name.Value,
+				"requestID":      request.Name,
+				"status":         status,
+				"errorCode":      errorCode,
+			}
+
+			quotaRequestInfoMetric.AddInfo(infoLabels)
+			quotaRequestLimitMetric.AddIfNotNil(labels, subRequest.Limit.Value)
+			if request.Properties.RequestSubmitTime != nil {
+				quotaRequestSubmitMetric.AddTime(labels, *request.Properties.RequestSubmitTime)
+			}
+		}
+	}
+}
+
+// quotaApiRequestIsFinished checks if quota request reached a terminal provisioning state
+func quotaApiRequestIsFinished(provisioningState string) bool {
+	switch strings.ToLower(provisioningState) {
+	case "succeeded", "failed", "invalid", "cancelled", "canceled":
+		return true
+	}
+	return false
+}
+
+// setupQuotaApiRequests registers quota request metrics
+func (m *MetricsCollectorAzureRmQuota) setupQuotaApiRequests() {
+	m.Collector.RegisterMetricList(
+		"quotaRequestInfo",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_quota_request_info",
+				Help: "Azure ResourceManager quota increase request information (status, errorCode)",
+			},
+			[]string{
+				"subscriptionID",
+				"location",
+				"provider",
+				"scope",
+				"quota",
+				"requestID",
+				"status",
+				"errorCode",
+			},
+		),
+		true,
+	)
+
+	requestLabels := []string{
+		"subscriptionID",
+		"location",
+		"provider",
+		"scope",
+		"quota",
+		"requestID",
+	}
+
+	m.Collector.RegisterMetricList(
+		"quotaRequestLimit",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_quota_request_limit",
+				Help: "Azure ResourceManager quota increase request requested limit",
+			},
+			requestLabels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"quotaRequestSubmit",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_quota_request_submit_timestamp",
+				Help: "Azure ResourceManager quota increase request submit timestamp",
+			},
+			requestLabels,
+		),
+		true,
+	)
+}
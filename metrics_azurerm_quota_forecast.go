@@ -0,0 +1,180 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	QuotaHistoryDataName = "quotaHistory"
+)
+
+type (
+	// quotaHistoryList contains usage history per quota (key: labels of azurerm_quota_current)
+	quotaHistoryList map[string]*quotaHistoryEntry
+
+	quotaHistoryEntry struct {
+		Labels  map[string]string    `json:"labels"`
+		Samples []quotaHistorySample `json:"samples"`
+	}
+
+	quotaHistorySample struct {
+		Time  int64   `json:"time"`
+		Value float64 `json:"value"`
+	}
+)
+
+// setupQuotaForecast registers quota forecast metrics
+func (m *MetricsCollectorAzureRmQuota) setupQuotaForecast() {
+	m.Collector.RegisterMetricList(
+		"quotaForecastExhaustion",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_quota_forecast_exhaustion_timestamp",
+				Help: "Azure ResourceManager quota forecasted exhaustion timestamp (based on usage trend)",
+			},
+			[]string{
+				"subscriptionID",
+				"location",
+				"provider",
+				"scope",
+				"quota",
+			},
+		),
+		true,
+	)
+}
+
+// collectQuotaForecast appends current quota usages to history (stored in collector cache) and forecasts quota exhaustion
+func (m *MetricsCollectorAzureRmQuota) collectQuotaForecast() {
+	quotaForecastExhaustionMetric := m.Collector.GetMetricList("quotaForecastExhaustion")
+	forecastConfig := Config.Collectors.Quota.Forecast
+
+	now := time.Now()
+	historyStart := now.Add(-forecastConfig.GetHistory()).Unix()
+	horizonEnd := now.Add(forecastConfig.GetHorizon()).Unix()
+	sampleInterval := int64(forecastConfig.GetSampleInterval().Seconds())
+	maxSamples := forecastConfig.GetMaxSamples()
+
+	limitList := map[string]float64{}
+	for _, row := range m.Collector.GetMetricList("quotaLimit").GetList() {
+		limitList[prometheusLabelsKey(row.Labels)] = row.Value
+	}
+
+	history := m.quotaHistory()
+	currentHistory := quotaHistoryList{}
+	for _, row := range m.Collector.GetMetricList("quotaCurrent").GetList() {
+		key := prometheusLabelsKey(row.Labels)
+
+		entry := &quotaHistoryEntry{Labels: row.Labels}
+		if val, exists := history[key]; exists {
+			entry = val
+		}
+
+		// remove samples outside of history window and append current value (at most one sample per sampleInterval)
+		samples := []quotaHistorySample{}
+		for _, sample := range entry.Samples {
+			if sample.Time >= historyStart {
+				samples = append(samples, sample)
+			}
+		}
+		if len(samples) == 0 || samples[len(samples)-1].Time+sampleInterval <= now.Unix() {
+			samples = append(samples, quotaHistorySample{Time: now.Unix(), Value: row.Value})
+		}
+
+		// keep newest samples if history contains more samples than allowed (eg. after change of sampleInterval)
+		if len(samples) > maxSamples {
+			samples = samples[len(samples)-maxSamples:]
+		}
+
+		entry.Samples = samples
+		currentHistory[key] = entry
+
+		limit, exists := limitList[key]
+		if !exists || limit <= 0 {
+			continue
+		}
+
+		if row.Value >= limit {
+			// already exhausted
+			quotaForecastExhaustionMetric.Add(entry.Labels, float64(now.Unix()))
+			continue
+		}
+
+		if len(entry.Samples) < forecastConfig.GetMinSamples() {
+			continue
+		}
+
+		if exhaustionTime, ok := quotaForecastExhaustion(entry.Samples, limit, forecastConfig.IsExponential(), now.Unix()); ok && exhaustionTime <= horizonEnd {
+			quotaForecastExhaustionMetric.Add(entry.Labels, float64(exhaustionTime))
+		}
+	}
+
+	// only keep history of existing quotas
+	m.Collector.SetData(QuotaHistoryDataName, currentHistory)
+}
+
+// quotaHistory returns quota history from collector data (or from cache file after restart)
+func (m *MetricsCollectorAzureRmQuota) quotaHistory() quotaHistoryList {
+	if history, ok := m.Collector.GetData(QuotaHistoryDataName).(quotaHistoryList); ok {
+		return history
+	}
+
+	history := quotaHistoryList{}
+	if restoreCollectorData(m.Collector, m.Logger(), QuotaHistoryDataName, &history, Config.Azure, Config.Collectors.Quota) {
+		m.Logger().Infof(`restored quota history of %v quotas from cache`, len(history))
+	}
+
+	return history
+}
+
+// quotaForecastExhaustion calculates timestamp when trend (linear or exponential regression) reaches limit
+// forecasts before now are skipped (trend is above limit while current usage is not)
+func quotaForecastExhaustion(samples []quotaHistorySample, limit float64, exponential bool, now int64) (int64, bool) {
+	baseTime := samples[0].Time
+
+	var sumX, sumY, sumXY, sumXX, count float64
+	for _, sample := range samples {
+		x := float64(sample.Time - baseTime)
+		y := sample.Value
+		if exponential {
+			if y <= 0 {
+				continue
+			}
+			y = math.Log(y)
+		}
+
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+		count++
+	}
+
+	denominator := count*sumXX - sumX*sumX
+	if count < 2 || denominator == 0 {
+		return 0, false
+	}
+
+	slope := (count*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / count
+
+	// no growth, no exhaustion
+	if slope <= 0 {
+		return 0, false
+	}
+
+	target := limit
+	if exponential {
+		target = math.Log(limit)
+	}
+
+	exhaustionTime := baseTime + int64((target-intercept)/slope)
+	if exhaustionTime < now {
+		return 0, false
+	}
+
+	return exhaustionTime, true
+}
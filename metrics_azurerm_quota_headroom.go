@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+)
+
+type (
+	// quotaFreeList contains free quota (limit - current) per location and quota name (lowercase)
+	quotaFreeList map[string]map[string]float64
+
+	// quotaHeadroomSkuCache caches vCPUs and restrictions of configured VM sizes per subscription and location
+	quotaHeadroomSkuCache struct {
+		lock sync.Mutex
+		list map[string]*quotaHeadroomSkuCacheEntry
+	}
+
+	quotaHeadroomSkuCacheEntry struct {
+		expiry time.Time
+		skus   []quotaHeadroomSku
+	}
+
+	quotaHeadroomSku struct {
+		Name       string
+		Family     string
+		VCPUs      float64
+		Restricted bool
+	}
+)
+
+// Add sets free quota of location and quota name
+func (l quotaFreeList) Add(location, quotaName string, limit, current float64) {
+	location = strings.ToLower(location)
+	if _, exists := l[location]; !exists {
+		l[location] = map[string]float64{}
+	}
+	l[location][strings.ToLower(quotaName)] = limit - current
+}
+
+// setupQuotaHeadroom registers quota headroom metrics
+func (m *MetricsCollectorAzureRmQuota) setupQuotaHeadroom() {
+	m.headroomSkuCache.list = map[string]*quotaHeadroomSkuCacheEntry{}
+
+	m.Collector.RegisterMetricList(
+		"quotaHeadroomInstances",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_quota_headroom_instances",
+				Help: "Azure ResourceManager quota headroom: number of additional VM instances deployable (regional and family vCPU quota)",
+			},
+			[]string{
+				"subscriptionID",
+				"location",
+				"vmSize",
+				"family",
+			},
+		),
+		true,
+	)
+}
+
+// collectQuotaHeadroom calculates the number of deployable instances per configured VM size based on regional and family vCPU quota
+// free quota is taken from compute usage collected in the same run
+func (m *MetricsCollectorAzureRmQuota) collectQuotaHeadroom(subscription *armsubscriptions.Subscription, computeQuotaFree quotaFreeList, logger *zap.SugaredLogger) {
+	quotaHeadroomInstancesMetric := m.Collector.GetMetricList("quotaHeadroomInstances")
+
+	for location, quotaFree := range computeQuotaFree {
+		locationLogger := logger.With(zap.String("location", location))
+
+		regionalFree, exists := quotaFree["cores"]
+		if !exists {
+			locationLogger.Warn(`unable to find regional vCPU quota`)
+			continue
+		}
+
+		for _, sku := range m.quotaHeadroomSkus(subscription, location, locationLogger) {
+			free := regionalFree
+			if familyFree, exists := quotaFree[strings.ToLower(sku.Family)]; exists {
+				free = math.Min(free, familyFree)
+			}
+
+			// size is not deployable if restricted for whole location
+			if sku.Restricted {
+				free = 0
+			}
+
+			instances := math.Max(math.Floor(free/sku.VCPUs), 0)
+
+			quotaHeadroomInstancesMetric.Add(prometheus.Labels{
+				"subscriptionID": to.StringLower(subscription.SubscriptionID),
+				"location":       location,
+				"vmSize":         sku.Name,
+				"family":         sku.Family,
+			}, instances)
+		}
+	}
+}
+
+// quotaHeadroomSkus returns configured VM sizes of location (cached for headroom.skuRefresh)
+func (m *MetricsCollectorAzureRmQuota) quotaHeadroomSkus(subscription *armsubscriptions.Subscription, location string, logger *zap.SugaredLogger) []quotaHeadroomSku {
+	cacheKey := to.StringLower(subscription.SubscriptionID) + ":" + location
+
+	m.headroomSkuCache.lock.Lock()
+	entry, exists := m.headroomSkuCache.list[cacheKey]
+	m.headroomSkuCache.lock.Unlock()
+	if exists && time.Now().Before(entry.expiry) {
+		return entry.skus
+	}
+
+	skuClient, err := armcompute.NewResourceSKUsClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	vmSizeList := map[string]bool{}
+	for _, vmSize := range Config.Collectors.Quota.Headroom.VmSizes {
+		vmSizeList[strings.ToLower(vmSize)] = true
+	}
+
+	skuList := []quotaHeadroomSku{}
+	skuPager := skuClient.NewListPager(&armcompute.ResourceSKUsClientListOptions{
+		Filter: to.StringPtr(fmt.Sprintf(`location eq '%s'`, location)),
+	})
+	for skuPager.More() {
+		result, err := skuPager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		for _, sku := range result.Value {
+			if !strings.EqualFold(to.String(sku.ResourceType), "virtualMachines") || !vmSizeList[to.StringLower(sku.Name)] {
+				continue
+			}
+
+			headroomSku := quotaHeadroomSku{
+				Name:   to.String(sku.Name),
+				Family: to.String(sku.Family),
+			}
+
+			for _, capability := range sku.Capabilities {
+				if strings.EqualFold(to.String(capability.Name), "vCPUs") {
+					if val, err := strconv.ParseFloat(to.String(capability.Value), 64); err == nil {
+						headroomSku.VCPUs = val
+					}
+				}
+			}
+
+			if headroomSku.VCPUs <= 0 {
+				continue
+			}
+
+			for _, restriction := range sku.Restrictions {
+				if restriction.Type != nil && *restriction.Type == armcompute.ResourceSKURestrictionsTypeLocation {
+					headroomSku.Restricted = true
+				}
+			}
+
+			skuList = append(skuList, headroomSku)
+		}
+	}
+
+	m.headroomSkuCache.lock.Lock()
+	m.headroomSkuCache.list[cacheKey] = &quotaHeadroomSkuCacheEntry{
+		expiry: time.Now().Add(Config.Collectors.Quota.Headroom.GetSkuRefresh()),
+		skus:   skuList,
+	}
+	m.headroomSkuCache.lock.Unlock()
+
+	return skuList
+}
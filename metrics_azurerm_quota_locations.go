@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+
+	"github.com/webdevops/azure-resourcemanager-exporter/config"
+)
+
+// discoverQuotaLocations returns configured locations and (if enabled) the discovered locations of subscription
+func (m *MetricsCollectorAzureRmQuota) discoverQuotaLocations(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) (list []string) {
+	locationList := map[string]string{}
+	addLocation := func(location string) {
+		location = normalizeQuotaLocation(location)
+		if location != "" && location != "global" {
+			locationList[location] = location
+		}
+	}
+
+	for _, location := range Config.Azure.Locations {
+		addLocation(location)
+	}
+
+	switch Config.Collectors.Quota.GetLocationDiscovery() {
+	case config.QuotaLocationDiscoveryResources:
+		resourceList, err := AzureClient.ListCachedResources(m.Context(), *subscription.SubscriptionID)
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		for _, resource := range resourceList {
+			addLocation(to.String(resource.Location))
+		}
+	case config.QuotaLocationDiscoverySubscription:
+		client, err := armsubscriptions.NewClient(AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		pager := client.NewListLocationsPager(*subscription.SubscriptionID, nil)
+		for pager.More() {
+			result, err := pager.NextPage(m.Context())
+			if err != nil {
+				logger.Panic(err)
+			}
+
+			for _, location := range result.Value {
+				// logical regions (eg. "europe") don't have quotas
+				if location.Metadata != nil && location.Metadata.RegionType != nil && *location.Metadata.RegionType != armsubscriptions.RegionTypePhysical {
+					continue
+				}
+				addLocation(to.String(location.Name))
+			}
+		}
+	}
+
+	for _, location := range locationList {
+		list = append(list, location)
+	}
+
+	logger.Debugf(`using %v quota locations`, len(list))
+
+	return
+}
+
+// quotaProviderLocations filters locations by availability of resource provider (only with location discovery)
+func (m *MetricsCollectorAzureRmQuota) quotaProviderLocations(subscription *armsubscriptions.Subscription, providerNamespace string, locations []string, logger *zap.SugaredLogger) (list []string) {
+	if !Config.Collectors.Quota.HasLocationDiscovery() {
+		return locations
+	}
+
+	provider, err := AzureClient.GetResourceProvider(m.Context(), *subscription.SubscriptionID, providerNamespace)
+	if err != nil || provider == nil {
+		// unable to detect availability, use all locations
+		return locations
+	}
+
+	providerLocations := map[string]bool{}
+	for _, resourceType := range provider.ResourceTypes {
+		for _, location := range resourceType.Locations {
+			providerLocations[normalizeQuotaLocation(to.String(location))] = true
+		}
+	}
+
+	if len(providerLocations) == 0 {
+		return locations
+	}
+
+	for _, location := range locations {
+		if providerLocations[location] {
+			list = append(list, location)
+		} else {
+			logger.Debugf(`skipping location "%v", provider "%v" is not available`, location, providerNamespace)
+		}
+	}
+
+	return
+}
+
+// normalizeQuotaLocation converts location display names (eg. "West Europe") to location names (eg. "westeurope")
+func normalizeQuotaLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
@@ -23,6 +23,9 @@ type MetricsCollectorAzureRmReservation struct {
 		reservationReservedHours         *prometheus.GaugeVec
 		reservationTotalReservedQuantity *prometheus.GaugeVec
 	}
+
+	// reservation locations from inventory (key: reservationID)
+	reservationLocations map[string]string
 }
 
 // Setup method to initialize Prometheus metrics
@@ -35,7 +38,10 @@ func (m *MetricsCollectorAzureRmReservation) Setup(collector *collector.Collecto
 		"reservationID",
 		"skuName",
 		"kind",
-		"usageDate",
+	}
+
+	if !Config.Collectors.Reservation.LatestOnly {
+		commonLabels = append(commonLabels, "usageDate")
 	}
 
 	m.prometheus.reservationInfo = prometheus.NewGaugeVec(
@@ -100,13 +106,54 @@ func (m *MetricsCollectorAzureRmReservation) Setup(collector *collector.Collecto
 		commonLabels,
 	)
 	m.Collector.RegisterMetricList("reservationTotalReservedQuantity", m.prometheus.reservationTotalReservedQuantity, true)
+
+	if Config.Collectors.Reservation.LatestOnly {
+		m.setupReservationUsageWindow(commonLabels)
+	}
+
+	if Config.Collectors.Reservation.Details {
+		m.setupReservationDetails()
+	}
+
+	if Config.Collectors.Reservation.Inventory {
+		m.setupReservationInventory()
+	}
+
+	if Config.Collectors.Reservation.Recommendations != nil {
+		m.setupReservationRecommendations()
+	}
+
+	if Config.Collectors.Reservation.SavingsPlans != nil {
+		m.setupSavingsPlans()
+	}
 }
 
 func (m *MetricsCollectorAzureRmReservation) Reset() {}
 
 func (m *MetricsCollectorAzureRmReservation) Collect(callback chan<- func()) {
+	// inventory first, reservation locations are used for reservation details
+	if Config.Collectors.Reservation.Inventory {
+		m.collectReservationInventory(logger)
+	}
+
 	for _, scope := range Config.Collectors.Reservation.Scopes {
 		m.collectReservationUsage(logger, scope, callback)
+
+		if Config.Collectors.Reservation.Details {
+			m.collectReservationDetails(logger.With(zap.String("scope", scope)), scope)
+		}
+
+		if Config.Collectors.Reservation.Recommendations != nil {
+			m.collectReservationRecommendations(logger.With(zap.String("scope", scope)), scope)
+		}
+
+		if Config.Collectors.Reservation.SavingsPlans != nil {
+			m.collectSavingsPlanUsage(logger.With(zap.String("scope", scope)), scope)
+		}
+	}
+
+	if Config.Collectors.Reservation.SavingsPlans != nil {
+		m.collectSavingsPlanInventory(logger)
 	}
 }
 
@@ -140,6 +187,8 @@ func (m *MetricsCollectorAzureRmReservation) collectReservationUsage(logger *zap
 		ReservationOrderID: nil,
 	})
 
+	usageSummaryList := []*armconsumption.ReservationSummary{}
+
 	// Collect and export metrics
 	for pager.More() {
 		page, err := pager.NextPage(m.Context())
@@ -148,6 +197,12 @@ func (m *MetricsCollectorAzureRmReservation) collectReservationUsage(logger *zap
 		}
 
 		for _, reservationProperties := range page.Value {
+			if Config.Collectors.Reservation.LatestOnly {
+				// aggregated after all pages are fetched
+				usageSummaryList = append(usageSummaryList, reservationProperties)
+				continue
+			}
+
 			labels := prometheus.Labels{
 				"scope":              scope,
 				"reservationOrderID": to.String(reservationProperties.Properties.ReservationOrderID),
@@ -166,4 +221,8 @@ func (m *MetricsCollectorAzureRmReservation) collectReservationUsage(logger *zap
 			reservationTotalReservedQuantity.AddIfNotNil(labels, reservationProperties.Properties.TotalReservedQuantity)
 		}
 	}
+
+	if Config.Collectors.Reservation.LatestOnly {
+		m.collectReservationUsageLatest(scope, usageSummaryList)
+	}
 }
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+)
+
+type (
+	reservationOrderResource struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Properties struct {
+			DisplayName       string     `json:"displayName"`
+			Term              string     `json:"term"`
+			BillingPlan       string     `json:"billingPlan"`
+			ProvisioningState string     `json:"provisioningState"`
+			OriginalQuantity  *float64   `json:"originalQuantity"`
+			RequestDateTime   *time.Time `json:"requestDateTime"`
+			CreatedDateTime   *time.Time `json:"createdDateTime"`
+			ExpiryDateTime    *time.Time `json:"expiryDateTime"`
+		} `json:"properties"`
+	}
+
+	reservationResource struct {
+		ID       string `json:"id"`
+		Location string `json:"location"`
+		Sku      struct {
+			Name string `json:"name"`
+		} `json:"sku"`
+		Properties struct {
+			DisplayName            string     `json:"displayName"`
+			ReservedResourceType   string     `json:"reservedResourceType"`
+			Term                   string     `json:"term"`
+			BillingPlan            string     `json:"billingPlan"`
+			AppliedScopeType       string     `json:"appliedScopeType"`
+			AppliedScopes          []string   `json:"appliedScopes"`
+			ProvisioningState      string     `json:"provisioningState"`
+			Renew                  bool       `json:"renew"`
+			Quantity               *float64   `json:"quantity"`
+			PurchaseDateTime       *time.Time `json:"purchaseDateTime"`
+			ExpiryDateTime         *time.Time `json:"expiryDateTime"`
+			AppliedScopeProperties *struct {
+				ManagementGroupID string `json:"managementGroupId"`
+				SubscriptionID    string `json:"subscriptionId"`
+				ResourceGroupID   string `json:"resourceGroupId"`
+			} `json:"appliedScopeProperties"`
+		} `json:"properties"`
+	}
+)
+
+// setupReservationInventory registers reservation order and reservation inventory metrics
+func (m *MetricsCollectorAzureRmReservation) setupReservationInventory() {
+	m.Collector.RegisterMetricList(
+		"reservationOrderInfo",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_order_info",
+				Help: "Azure ResourceManager Reservation Order information",
+			},
+			[]string{
+				"reservationOrderID",
+				"displayName",
+				"term",
+				"billingPlan",
+				"provisioningState",
+			},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationOrderQuantity",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_order_quantity",
+				Help: "Azure ResourceManager Reservation Order original quantity",
+			},
+			[]string{"reservationOrderID"},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationOrderPurchase",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_order_purchase_timestamp",
+				Help: "Azure ResourceManager Reservation Order purchase timestamp",
+			},
+			[]string{"reservationOrderID"},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationOrderExpiry",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_order_expiry_timestamp",
+				Help: "Azure ResourceManager Reservation Order expiry timestamp",
+			},
+			[]string{"reservationOrderID"},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationInventoryInfo",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_inventory_info",
+				Help: "Azure ResourceManager Reservation information (inventory)",
+			},
+			[]string{
+				"reservationOrderID",
+				"reservationID",
+				"displayName",
+				"skuName",
+				"location",
+				"resourceType",
+				"term",
+				"billingPlan",
+				"appliedScopeType",
+				"appliedScopes",
+				"provisioningState",
+				"renew",
+			},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationQuantity",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_quantity",
+				Help: "Azure ResourceManager Reservation quantity",
+			},
+			[]string{"reservationOrderID", "reservationID"},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationPurchase",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_purchase_timestamp",
+				Help: "Azure ResourceManager Reservation purchase timestamp",
+			},
+			[]string{"reservationOrderID", "reservationID"},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationExpiry",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_expiry_timestamp",
+				Help: "Azure ResourceManager Reservation expiry timestamp",
+			},
+			[]string{"reservationOrderID", "reservationID"},
+		),
+		true,
+	)
+}
+
+// collectReservationInventory collects all visible reservation orders and reservations
+func (m *MetricsCollectorAzureRmReservation) collectReservationInventory(logger *zap.SugaredLogger) {
+	reservationOrderInfo := m.Collector.GetMetricList("reservationOrderInfo")
+	reservationOrderQuantity := m.Collector.GetMetricList("reservationOrderQuantity")
+	reservationOrderPurchase := m.Collector.GetMetricList("reservationOrderPurchase")
+	reservationOrderExpiry := m.Collector.GetMetricList("reservationOrderExpiry")
+	reservationInventoryInfo := m.Collector.GetMetricList("reservationInventoryInfo")
+	reservationQuantity := m.Collector.GetMetricList("reservationQuantity")
+	reservationPurchase := m.Collector.GetMetricList("reservationPurchase")
+	reservationExpiry := m.Collector.GetMetricList("reservationExpiry")
+
+	logger.Info(`fetching reservation orders`)
+	reservationOrders, err := listArmApiResources(m.Context(), "/providers/Microsoft.Capacity/reservationOrders", url.Values{"api-version": {"2022-11-01"}})
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	for _, row := range reservationOrders {
+		reservationOrder := reservationOrderResource{}
+		if err := json.Unmarshal(row, &reservationOrder); err != nil {
+			logger.Panic(err)
+		}
+
+		labels := prometheus.Labels{
+			"reservationOrderID": stringToStringLower(reservationOrder.Name),
+		}
+
+		infoLabels := prometheus.Labels{
+			"reservationOrderID": stringToStringLower(reservationOrder.Name),
+			"displayName":        reservationOrder.Properties.DisplayName,
+			"term":               reservationOrder.Properties.Term,
+			"billingPlan":        reservationOrder.Properties.BillingPlan,
+			"provisioningState":  reservationOrder.Properties.ProvisioningState,
+		}
+
+		purchaseTime := reservationOrder.Properties.CreatedDateTime
+		if purchaseTime == nil {
+			purchaseTime = reservationOrder.Properties.RequestDateTime
+		}
+
+		reservationOrderInfo.AddInfo(infoLabels)
+		reservationOrderQuantity.AddIfNotNil(labels, reservationOrder.Properties.OriginalQuantity)
+		if purchaseTime != nil {
+			reservationOrderPurchase.AddTime(labels, *purchaseTime)
+		}
+		if reservationOrder.Properties.ExpiryDateTime != nil {
+			reservationOrderExpiry.AddTime(labels, *reservationOrder.Properties.ExpiryDateTime)
+		}
+	}
+
+	logger.Info(`fetching reservations`)
+	m.reservationLocations = map[string]string{}
+	reservations, err := listArmApiResources(m.Context(), "/providers/Microsoft.Capacity/reservations", url.Values{"api-version": {"2022-11-01"}})
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	for _, row := range reservations {
+		reservation := reservationResource{}
+		if err := json.Unmarshal(row, &reservation); err != nil {
+			logger.Panic(err)
+		}
+
+		// /providers/microsoft.capacity/reservationOrders/{reservationOrderId}/reservations/{reservationId}
+		reservationOrderID, reservationID := parseBenefitResourceId(reservation.ID, "reservationOrders", "reservations")
+
+		appliedScopes := reservation.Properties.AppliedScopes
+		if len(appliedScopes) == 0 && reservation.Properties.AppliedScopeProperties != nil {
+			// appliedScopes is not set for management group and resource group scopes
+			for _, scope := range []string{
+				reservation.Properties.AppliedScopeProperties.ManagementGroupID,
+				reservation.Properties.AppliedScopeProperties.ResourceGroupID,
+				reservation.Properties.AppliedScopeProperties.SubscriptionID,
+			} {
+				if scope != "" {
+					appliedScopes = append(appliedScopes, scope)
+					break
+				}
+			}
+		}
+
+		labels := prometheus.Labels{
+			"reservationOrderID": reservationOrderID,
+			"reservationID":      reservationID,
+		}
+
+		infoLabels := prometheus.Labels{
+			"reservationOrderID": reservationOrderID,
+			"reservationID":      reservationID,
+			"displayName":        reservation.Properties.DisplayName,
+			"skuName":            reservation.Sku.Name,
+			"location":           stringToStringLower(reservation.Location),
+			"resourceType":       reservation.Properties.ReservedResourceType,
+			"term":               reservation.Properties.Term,
+			"billingPlan":        reservation.Properties.BillingPlan,
+			"appliedScopeType":   reservation.Properties.AppliedScopeType,
+			"appliedScopes":      strings.Join(appliedScopes, ","),
+			"provisioningState":  reservation.Properties.ProvisioningState,
+			"renew":              to.BoolString(reservation.Properties.Renew),
+		}
+
+		m.reservationLocations[reservationID] = stringToStringLower(reservation.Location)
+
+		reservationInventoryInfo.AddInfo(infoLabels)
+		reservationQuantity.AddIfNotNil(labels, reservation.Properties.Quantity)
+		if reservation.Properties.PurchaseDateTime != nil {
+			reservationPurchase.AddTime(labels, *reservation.Properties.PurchaseDateTime)
+		}
+		if reservation.Properties.ExpiryDateTime != nil {
+			reservationExpiry.AddTime(labels, *reservation.Properties.ExpiryDateTime)
+		}
+	}
+}
+
+// parseBenefitResourceId returns order and item ID (lowercase) of a benefit resource ID
+// eg. /providers/Microsoft.Capacity/reservationOrders/{reservationOrderId}/reservations/{reservationId}
+func parseBenefitResourceId(resourceId, orderType, itemType string) (orderID, itemID string) {
+	idParts := strings.Split(strings.Trim(resourceId, "/"), "/")
+	for i := 0; i+1 < len(idParts); i++ {
+		switch {
+		case strings.EqualFold(idParts[i], orderType):
+			orderID = stringToStringLower(idParts[i+1])
+		case strings.EqualFold(idParts[i], itemType):
+			itemID = stringToStringLower(idParts[i+1])
+		}
+	}
+	return
+}
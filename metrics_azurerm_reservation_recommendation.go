@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/consumption/armconsumption"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+)
+
+// setupReservationRecommendations registers reservation purchase recommendation metrics
+func (m *MetricsCollectorAzureRmReservation) setupReservationRecommendations() {
+	commonLabels := []string{
+		"recommendationID",
+		"scope",
+		"scopeType",
+		"subscriptionID",
+		"lookBackPeriod",
+		"term",
+		"resourceType",
+		"skuName",
+		"location",
+		"currency",
+	}
+
+	m.Collector.RegisterMetricList(
+		"reservationRecommendationQuantity",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_recommendation_quantity",
+				Help: "Azure ResourceManager Reservation recommendation quantity",
+			},
+			commonLabels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationRecommendationNetSavings",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_recommendation_net_savings",
+				Help: "Azure ResourceManager Reservation recommendation net savings",
+			},
+			commonLabels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationRecommendationCostWithReservation",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_recommendation_cost_with_reservation",
+				Help: "Azure ResourceManager Reservation recommendation total cost with reserved instances",
+			},
+			commonLabels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationRecommendationCostWithoutReservation",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_recommendation_cost_without_reservation",
+				Help: "Azure ResourceManager Reservation recommendation total cost without reserved instances",
+			},
+			commonLabels,
+		),
+		true,
+	)
+}
+
+// collectReservationRecommendations collects reservation purchase recommendations for all configured scope types, look back periods and resource types
+func (m *MetricsCollectorAzureRmReservation) collectReservationRecommendations(logger *zap.SugaredLogger, scope string) {
+	quantityMetric := m.Collector.GetMetricList("reservationRecommendationQuantity")
+	netSavingsMetric := m.Collector.GetMetricList("reservationRecommendationNetSavings")
+	costWithReservationMetric := m.Collector.GetMetricList("reservationRecommendationCostWithReservation")
+	costWithoutReservationMetric := m.Collector.GetMetricList("reservationRecommendationCostWithoutReservation")
+
+	recommendationConfig := Config.Collectors.Reservation.Recommendations
+
+	client, err := armconsumption.NewReservationRecommendationsClient(AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	for _, scopeType := range recommendationConfig.GetScopeTypes() {
+		for _, lookBackPeriod := range recommendationConfig.GetLookBackPeriods() {
+			for _, resourceType := range recommendationConfig.GetResourceTypes() {
+				recommendationLogger := logger.With(
+					zap.String("scopeType", scopeType),
+					zap.String("lookBackPeriod", lookBackPeriod),
+					zap.String("resourceType", resourceType),
+				)
+				recommendationLogger.Info(`fetching reservation recommendations`)
+
+				filter := fmt.Sprintf(
+					`properties/scope eq '%s' and properties/lookBackPeriod eq '%s' and properties/resourceType eq '%s'`,
+					scopeType,
+					lookBackPeriod,
+					resourceType,
+				)
+
+				pager := client.NewListPager(strings.TrimPrefix(scope, "/"), &armconsumption.ReservationRecommendationsClientListOptions{
+					Filter: to.Ptr(filter),
+				})
+
+				for pager.More() {
+					page, err := pager.NextPage(m.Context())
+					if err != nil {
+						recommendationLogger.Panic(err)
+					}
+
+					for _, row := range page.Value {
+						labels := prometheus.Labels{
+							"recommendationID": "",
+							"scope":            scope,
+							"scopeType":        scopeType,
+							"subscriptionID":   "",
+							"lookBackPeriod":   lookBackPeriod,
+							"term":             "",
+							"resourceType":     resourceType,
+							"skuName":          "",
+							"location":         "",
+							"currency":         "",
+						}
+
+						var quantity, netSavings, costWithReservation, costWithoutReservation *float64
+
+						switch recommendation := row.(type) {
+						case *armconsumption.LegacyReservationRecommendation:
+							if recommendation.Properties == nil {
+								continue
+							}
+							properties := recommendation.Properties.GetLegacyReservationRecommendationProperties()
+
+							labels["recommendationID"] = to.StringLower(recommendation.Name)
+
+							if singleScopeProperties, ok := recommendation.Properties.(*armconsumption.LegacySingleScopeReservationRecommendationProperties); ok {
+								labels["subscriptionID"] = to.StringLower(singleScopeProperties.SubscriptionID)
+							}
+
+							labels["term"] = to.String(properties.Term)
+							labels["skuName"] = to.String(recommendation.SKU)
+							labels["location"] = to.StringLower(recommendation.Location)
+
+							quantity = properties.RecommendedQuantity
+							netSavings = properties.NetSavings
+							costWithReservation = properties.TotalCostWithReservedInstances
+							costWithoutReservation = properties.CostWithNoReservedInstances
+						case *armconsumption.ModernReservationRecommendation:
+							if recommendation.Properties == nil {
+								continue
+							}
+							properties := recommendation.Properties
+
+							// modern (MCA) recommendations don't provide the subscription of Single scope recommendations,
+							// recommendationID keeps them as separate series
+							labels["recommendationID"] = to.StringLower(recommendation.Name)
+							labels["term"] = to.String(properties.Term)
+							labels["skuName"] = to.String(properties.SKUName)
+							labels["location"] = to.StringLower(properties.Location)
+
+							quantity = properties.RecommendedQuantity
+							if properties.NetSavings != nil {
+								labels["currency"] = to.StringLower(properties.NetSavings.Currency)
+								netSavings = properties.NetSavings.Value
+							}
+							if properties.TotalCostWithReservedInstances != nil {
+								costWithReservation = properties.TotalCostWithReservedInstances.Value
+							}
+							if properties.CostWithNoReservedInstances != nil {
+								costWithoutReservation = properties.CostWithNoReservedInstances.Value
+							}
+						default:
+							continue
+						}
+
+						if !reservationRecommendationTermEnabled(labels["term"]) {
+							continue
+						}
+
+						quantityMetric.AddIfNotNil(labels, quantity)
+						netSavingsMetric.AddIfNotNil(labels, netSavings)
+						costWithReservationMetric.AddIfNotNil(labels, costWithReservation)
+						costWithoutReservationMetric.AddIfNotNil(labels, costWithoutReservation)
+					}
+				}
+			}
+		}
+	}
+}
+
+// reservationRecommendationTermEnabled checks if term (eg. P1Y) is enabled in config
+func reservationRecommendationTermEnabled(term string) bool {
+	for _, val := range Config.Collectors.Reservation.Recommendations.GetTerms() {
+		if strings.EqualFold(val, term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+)
+
+type (
+	savingsPlanOrderResource struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Sku  struct {
+			Name string `json:"name"`
+		} `json:"sku"`
+		Properties struct {
+			DisplayName       string     `json:"displayName"`
+			Term              string     `json:"term"`
+			BillingPlan       string     `json:"billingPlan"`
+			BillingScopeID    string     `json:"billingScopeId"`
+			ProvisioningState string     `json:"provisioningState"`
+			BenefitStartTime  *time.Time `json:"benefitStartTime"`
+			ExpiryDateTime    *time.Time `json:"expiryDateTime"`
+		} `json:"properties"`
+	}
+
+	savingsPlanResource struct {
+		ID  string `json:"id"`
+		Sku struct {
+			Name string `json:"name"`
+		} `json:"sku"`
+		Properties struct {
+			DisplayName            string `json:"displayName"`
+			Term                   string `json:"term"`
+			BillingPlan            string `json:"billingPlan"`
+			BillingScopeID         string `json:"billingScopeId"`
+			AppliedScopeType       string `json:"appliedScopeType"`
+			AppliedScopeProperties *struct {
+				ManagementGroupID string `json:"managementGroupId"`
+				SubscriptionID    string `json:"subscriptionId"`
+				ResourceGroupID   string `json:"resourceGroupId"`
+			} `json:"appliedScopeProperties"`
+			ProvisioningState string `json:"provisioningState"`
+			Renew             bool   `json:"renew"`
+			Commitment        *struct {
+				Grain        string   `json:"grain"`
+				CurrencyCode string   `json:"currencyCode"`
+				Amount       *float64 `json:"amount"`
+			} `json:"commitment"`
+			PurchaseDateTime *time.Time `json:"purchaseDateTime"`
+			ExpiryDateTime   *time.Time `json:"expiryDateTime"`
+		} `json:"properties"`
+	}
+
+	benefitUtilizationSummaryResource struct {
+		Kind       string `json:"kind"`
+		Properties struct {
+			ArmSkuName               string   `json:"armSkuName"`
+			BenefitID                string   `json:"benefitId"`
+			BenefitOrderID           string   `json:"benefitOrderId"`
+			UsageDate                string   `json:"usageDate"`
+			AvgUtilizationPercentage *float64 `json:"avgUtilizationPercentage"`
+			MinUtilizationPercentage *float64 `json:"minUtilizationPercentage"`
+			MaxUtilizationPercentage *float64 `json:"maxUtilizationPercentage"`
+		} `json:"properties"`
+	}
+)
+
+// setupSavingsPlans registers savings plan inventory and utilization metrics
+func (m *MetricsCollectorAzureRmReservation) setupSavingsPlans() {
+	m.Collector.RegisterMetricList(
+		"savingsPlanOrderInfo",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_savingsplan_order_info",
+				Help: "Azure ResourceManager SavingsPlan Order information",
+			},
+			[]string{
+				"savingsPlanOrderID",
+				"displayName",
+				"skuName",
+				"term",
+				"billingPlan",
+				"billingScopeID",
+				"provisioningState",
+			},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"savingsPlanOrderExpiry",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_savingsplan_order_expiry_timestamp",
+				Help: "Azure ResourceManager SavingsPlan Order expiry timestamp",
+			},
+			[]string{"savingsPlanOrderID"},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"savingsPlanInfo",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_savingsplan_info",
+				Help: "Azure ResourceManager SavingsPlan information",
+			},
+			[]string{
+				"savingsPlanOrderID",
+				"savingsPlanID",
+				"displayName",
+				"skuName",
+				"term",
+				"billingPlan",
+				"billingScopeID",
+				"appliedScopeType",
+				"appliedScope",
+				"provisioningState",
+				"renew",
+			},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"savingsPlanCommitment",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_savingsplan_commitment",
+				Help: "Azure ResourceManager SavingsPlan commitment amount (per grain)",
+			},
+			[]string{"savingsPlanOrderID", "savingsPlanID", "grain", "currency"},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"savingsPlanPurchase",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_savingsplan_purchase_timestamp",
+				Help: "Azure ResourceManager SavingsPlan purchase timestamp",
+			},
+			[]string{"savingsPlanOrderID", "savingsPlanID"},
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"savingsPlanExpiry",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_savingsplan_expiry_timestamp",
+				Help: "Azure ResourceManager SavingsPlan expiry timestamp",
+			},
+			[]string{"savingsPlanOrderID", "savingsPlanID"},
+		),
+		true,
+	)
+
+	utilizationLabels := []string{
+		"scope",
+		"savingsPlanOrderID",
+		"savingsPlanID",
+		"skuName",
+		"kind",
+	}
+
+	if !Config.Collectors.Reservation.LatestOnly {
+		utilizationLabels = append(utilizationLabels, "usageDate")
+	}
+
+	m.Collector.RegisterMetricList(
+		"savingsPlanUsage",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_savingsplan_utilization",
+				Help: "Azure ResourceManager SavingsPlan Utilization",
+			},
+			utilizationLabels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"savingsPlanMinUsage",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_savingsplan_utilization_min",
+				Help: "Azure ResourceManager SavingsPlan Min Utilization",
+			},
+			utilizationLabels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"savingsPlanMaxUsage",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_savingsplan_utilization_max",
+				Help: "Azure ResourceManager SavingsPlan Max Utilization",
+			},
+			utilizationLabels,
+		),
+		true,
+	)
+}
+
+// collectSavingsPlanInventory collects all visible savings plan orders and savings plans
+func (m *MetricsCollectorAzureRmReservation) collectSavingsPlanInventory(logger *zap.SugaredLogger) {
+	savingsPlanOrderInfo := m.Collector.GetMetricList("savingsPlanOrderInfo")
+	savingsPlanOrderExpiry := m.Collector.GetMetricList("savingsPlanOrderExpiry")
+	savingsPlanInfo := m.Collector.GetMetricList("savingsPlanInfo")
+	savingsPlanCommitment := m.Collector.GetMetricList("savingsPlanCommitment")
+	savingsPlanPurchase := m.Collector.GetMetricList("savingsPlanPurchase")
+	savingsPlanExpiry := m.Collector.GetMetricList("savingsPlanExpiry")
+
+	logger.Info(`fetching savings plan orders`)
+	savingsPlanOrders, err := listArmApiResources(m.Context(), "/providers/Microsoft.BillingBenefits/savingsPlanOrders", url.Values{"api-version": {"2022-11-01"}})
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	for _, row := range savingsPlanOrders {
+		savingsPlanOrder := savingsPlanOrderResource{}
+		if err := json.Unmarshal(row, &savingsPlanOrder); err != nil {
+			logger.Panic(err)
+		}
+
+		labels := prometheus.Labels{
+			"savingsPlanOrderID": stringToStringLower(savingsPlanOrder.Name),
+		}
+
+		infoLabels := prometheus.Labels{
+			"savingsPlanOrderID": stringToStringLower(savingsPlanOrder.Name),
+			"displayName":        savingsPlanOrder.Properties.DisplayName,
+			"skuName":            savingsPlanOrder.Sku.Name,
+			"term":               savingsPlanOrder.Properties.Term,
+			"billingPlan":        savingsPlanOrder.Properties.BillingPlan,
+			"billingScopeID":     savingsPlanOrder.Properties.BillingScopeID,
+			"provisioningState":  savingsPlanOrder.Properties.ProvisioningState,
+		}
+
+		savingsPlanOrderInfo.AddInfo(infoLabels)
+		if savingsPlanOrder.Properties.ExpiryDateTime != nil {
+			savingsPlanOrderExpiry.AddTime(labels, *savingsPlanOrder.Properties.ExpiryDateTime)
+		}
+	}
+
+	logger.Info(`fetching savings plans`)
+	savingsPlans, err := listArmApiResources(m.Context(), "/providers/Microsoft.BillingBenefits/savingsPlans", url.Values{"api-version": {"2022-11-01"}})
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	for _, row := range savingsPlans {
+		savingsPlan := savingsPlanResource{}
+		if err := json.Unmarshal(row, &savingsPlan); err != nil {
+			logger.Panic(err)
+		}
+
+		// /providers/Microsoft.BillingBenefits/savingsPlanOrders/{savingsPlanOrderId}/savingsPlans/{savingsPlanId}
+		savingsPlanOrderID, savingsPlanID := parseBenefitResourceId(savingsPlan.ID, "savingsPlanOrders", "savingsPlans")
+
+		appliedScope := ""
+		if savingsPlan.Properties.AppliedScopeProperties != nil {
+			for _, scope := range []string{
+				savingsPlan.Properties.AppliedScopeProperties.ManagementGroupID,
+				savingsPlan.Properties.AppliedScopeProperties.ResourceGroupID,
+				savingsPlan.Properties.AppliedScopeProperties.SubscriptionID,
+			} {
+				if scope != "" {
+					appliedScope = scope
+					break
+				}
+			}
+		}
+
+		labels := prometheus.Labels{
+			"savingsPlanOrderID": savingsPlanOrderID,
+			"savingsPlanID":      savingsPlanID,
+		}
+
+		infoLabels := prometheus.Labels{
+			"savingsPlanOrderID": savingsPlanOrderID,
+			"savingsPlanID":      savingsPlanID,
+			"displayName":        savingsPlan.Properties.DisplayName,
+			"skuName":            savingsPlan.Sku.Name,
+			"term":               savingsPlan.Properties.Term,
+			"billingPlan":        savingsPlan.Properties.BillingPlan,
+			"billingScopeID":     savingsPlan.Properties.BillingScopeID,
+			"appliedScopeType":   savingsPlan.Properties.AppliedScopeType,
+			"appliedScope":       appliedScope,
+			"provisioningState":  savingsPlan.Properties.ProvisioningState,
+			"renew":              to.BoolString(savingsPlan.Properties.Renew),
+		}
+
+		savingsPlanInfo.AddInfo(infoLabels)
+
+		if savingsPlan.Properties.Commitment != nil {
+			savingsPlanCommitment.AddIfNotNil(prometheus.Labels{
+				"savingsPlanOrderID": savingsPlanOrderID,
+				"savingsPlanID":      savingsPlanID,
+				"grain":              savingsPlan.Properties.Commitment.Grain,
+				"currency":           stringToStringLower(savingsPlan.Properties.Commitment.CurrencyCode),
+			}, savingsPlan.Properties.Commitment.Amount)
+		}
+
+		if savingsPlan.Properties.PurchaseDateTime != nil {
+			savingsPlanPurchase.AddTime(labels, *savingsPlan.Properties.PurchaseDateTime)
+		}
+		if savingsPlan.Properties.ExpiryDateTime != nil {
+			savingsPlanExpiry.AddTime(labels, *savingsPlan.Properties.ExpiryDateTime)
+		}
+	}
+}
+
+// collectSavingsPlanUsage collects savings plan utilization (benefit utilization summaries) for billing scope
+func (m *MetricsCollectorAzureRmReservation) collectSavingsPlanUsage(logger *zap.SugaredLogger, scope string) {
+	savingsPlanUsage := m.Collector.GetMetricList("savingsPlanUsage")
+	savingsPlanMinUsage := m.Collector.GetMetricList("savingsPlanMinUsage")
+	savingsPlanMaxUsage := m.Collector.GetMetricList("savingsPlanMaxUsage")
+
+	days := Config.Collectors.Reservation.FromDays
+	granularity := Config.Collectors.Reservation.GetSavingsPlanGranularity()
+
+	startDate := time.Now().AddDate(0, 0, -days).Format("2006-01-02")
+	endDate := time.Now().Format("2006-01-02")
+
+	query := url.Values{
+		"api-version":    {"2023-08-01"},
+		"grainParameter": {strings.ToUpper(granularity[:1]) + strings.ToLower(granularity[1:])},
+		"$filter":        {fmt.Sprintf(`properties/usageDate ge '%s' and properties/usageDate le '%s'`, startDate, endDate)},
+	}
+
+	logger.Info(`fetching savings plan utilization`)
+	summaries, err := listArmApiResources(m.Context(), scope+"/providers/Microsoft.CostManagement/benefitUtilizationSummaries", query)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	// current period is not complete yet
+	now := time.Now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch strings.ToLower(granularity) {
+	case "hourly":
+		periodStart = now.Truncate(time.Hour)
+	case "monthly":
+		periodStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	// latest complete period per savings plan (latestOnly)
+	latestList := map[string]*benefitUtilizationSummaryResource{}
+	latestUsageDate := map[string]time.Time{}
+	latestLabels := map[string]prometheus.Labels{}
+
+	for _, row := range summaries {
+		summary := benefitUtilizationSummaryResource{}
+		if err := json.Unmarshal(row, &summary); err != nil {
+			logger.Panic(err)
+		}
+
+		if !strings.EqualFold(summary.Kind, "SavingsPlan") {
+			continue
+		}
+
+		savingsPlanOrderID, savingsPlanID := parseBenefitResourceId(summary.Properties.BenefitID, "savingsPlanOrders", "savingsPlans")
+		if savingsPlanOrderID == "" {
+			savingsPlanOrderID, _ = parseBenefitResourceId(summary.Properties.BenefitOrderID, "savingsPlanOrders", "savingsPlans")
+		}
+
+		labels := prometheus.Labels{
+			"scope":              scope,
+			"savingsPlanOrderID": savingsPlanOrderID,
+			"savingsPlanID":      savingsPlanID,
+			"skuName":            summary.Properties.ArmSkuName,
+			"kind":               summary.Kind,
+		}
+
+		if Config.Collectors.Reservation.LatestOnly {
+			usageDate, err := time.Parse(time.RFC3339, summary.Properties.UsageDate)
+			if err != nil || !usageDate.Before(periodStart) {
+				continue
+			}
+
+			key := prometheusLabelsKey(labels)
+			if _, exists := latestList[key]; !exists || usageDate.After(latestUsageDate[key]) {
+				latestList[key] = &summary
+				latestUsageDate[key] = usageDate
+				latestLabels[key] = labels
+			}
+			continue
+		}
+
+		labels["usageDate"] = summary.Properties.UsageDate
+
+		savingsPlanUsage.AddIfNotNil(labels, summary.Properties.AvgUtilizationPercentage)
+		savingsPlanMinUsage.AddIfNotNil(labels, summary.Properties.MinUtilizationPercentage)
+		savingsPlanMaxUsage.AddIfNotNil(labels, summary.Properties.MaxUtilizationPercentage)
+	}
+
+	for key, summary := range latestList {
+		savingsPlanUsage.AddIfNotNil(latestLabels[key], summary.Properties.AvgUtilizationPercentage)
+		savingsPlanMinUsage.AddIfNotNil(latestLabels[key], summary.Properties.MinUtilizationPercentage)
+		savingsPlanMaxUsage.AddIfNotNil(latestLabels[key], summary.Properties.MaxUtilizationPercentage)
+	}
+}
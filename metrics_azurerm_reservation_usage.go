@@ -0,0 +1,301 @@
+package main
+
+import (
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/consumption/armconsumption"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/azuresdk/armclient"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+)
+
+type (
+	// reservationUsageWindow contains latest complete period and window aggregation of one reservation
+	reservationUsageWindow struct {
+		labels prometheus.Labels
+		latest *armconsumption.ReservationSummaryProperties
+
+		minUsage   *float64
+		maxUsage   *float64
+		avgUsage   float64
+		avgCount   float64
+		usedHours  float64
+		totalHours float64
+	}
+
+	// reservationResourceUsage contains aggregated reservation usage of one consuming resource
+	reservationResourceUsage struct {
+		labels        prometheus.Labels
+		usedHours     float64
+		lastUsageDate *time.Time
+	}
+)
+
+// setupReservationUsageWindow registers reservation utilization aggregation metrics (latestOnly mode)
+func (m *MetricsCollectorAzureRmReservation) setupReservationUsageWindow(labels []string) {
+	m.Collector.RegisterMetricList(
+		"reservationWindowAvgUsage",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_utilization_window_avg",
+				Help: "Azure ResourceManager Reservation average Utilization over fromDays",
+			},
+			labels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationWindowMinUsage",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_utilization_window_min",
+				Help: "Azure ResourceManager Reservation Min Utilization over fromDays",
+			},
+			labels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationWindowMaxUsage",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_utilization_window_max",
+				Help: "Azure ResourceManager Reservation Max Utilization over fromDays",
+			},
+			labels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationWindowUsedHours",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_used_hours_window",
+				Help: "Azure ResourceManager Reservation Used Hours over fromDays",
+			},
+			labels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationWindowReservedHours",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_reserved_hours_window",
+				Help: "Azure ResourceManager Reservation Reserved Hours over fromDays",
+			},
+			labels,
+		),
+		true,
+	)
+}
+
+// collectReservationUsageLatest exports the latest complete period and the window aggregation of reservation summaries
+func (m *MetricsCollectorAzureRmReservation) collectReservationUsageLatest(scope string, summaryList []*armconsumption.ReservationSummary) {
+	reservationInfo := m.Collector.GetMetricList("reservationInfo")
+	reservationUsage := m.Collector.GetMetricList("reservationUsage")
+	reservationMinUsage := m.Collector.GetMetricList("reservationMinUsage")
+	reservationMaxUsage := m.Collector.GetMetricList("reservationMaxUsage")
+	reservationUsedHours := m.Collector.GetMetricList("reservationUsedHours")
+	reservationReservedHours := m.Collector.GetMetricList("reservationReservedHours")
+	reservationTotalReservedQuantity := m.Collector.GetMetricList("reservationTotalReservedQuantity")
+	reservationWindowAvgUsage := m.Collector.GetMetricList("reservationWindowAvgUsage")
+	reservationWindowMinUsage := m.Collector.GetMetricList("reservationWindowMinUsage")
+	reservationWindowMaxUsage := m.Collector.GetMetricList("reservationWindowMaxUsage")
+	reservationWindowUsedHours := m.Collector.GetMetricList("reservationWindowUsedHours")
+	reservationWindowReservedHours := m.Collector.GetMetricList("reservationWindowReservedHours")
+
+	// current period is not complete yet
+	now := time.Now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if stringToStringLower(Config.Collectors.Reservation.Granularity) == "monthly" {
+		periodStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	windowList := map[string]*reservationUsageWindow{}
+	for _, summary := range summaryList {
+		properties := summary.Properties
+		if properties == nil || properties.UsageDate == nil || !properties.UsageDate.Before(periodStart) {
+			continue
+		}
+
+		labels := prometheus.Labels{
+			"scope":              scope,
+			"reservationOrderID": to.String(properties.ReservationOrderID),
+			"reservationID":      to.String(properties.ReservationID),
+			"skuName":            to.String(properties.SKUName),
+			"kind":               to.String(properties.Kind),
+		}
+
+		key := prometheusLabelsKey(labels)
+		if _, exists := windowList[key]; !exists {
+			windowList[key] = &reservationUsageWindow{labels: labels}
+		}
+		window := windowList[key]
+
+		if window.latest == nil || properties.UsageDate.After(*window.latest.UsageDate) {
+			window.latest = properties
+		}
+
+		if properties.MinUtilizationPercentage != nil && (window.minUsage == nil || *properties.MinUtilizationPercentage < *window.minUsage) {
+			window.minUsage = properties.MinUtilizationPercentage
+		}
+
+		if properties.MaxUtilizationPercentage != nil && (window.maxUsage == nil || *properties.MaxUtilizationPercentage > *window.maxUsage) {
+			window.maxUsage = properties.MaxUtilizationPercentage
+		}
+
+		if properties.AvgUtilizationPercentage != nil {
+			window.avgUsage += *properties.AvgUtilizationPercentage
+			window.avgCount++
+		}
+
+		window.usedHours += to.Float64(properties.UsedHours)
+		window.totalHours += to.Float64(properties.ReservedHours)
+	}
+
+	for _, window := range windowList {
+		reservationInfo.AddInfo(window.labels)
+		reservationUsage.AddIfNotNil(window.labels, window.latest.AvgUtilizationPercentage)
+		reservationMinUsage.AddIfNotNil(window.labels, window.latest.MinUtilizationPercentage)
+		reservationMaxUsage.AddIfNotNil(window.labels, window.latest.MaxUtilizationPercentage)
+		reservationUsedHours.AddIfNotNil(window.labels, window.latest.UsedHours)
+		reservationReservedHours.AddIfNotNil(window.labels, window.latest.ReservedHours)
+		reservationTotalReservedQuantity.AddIfNotNil(window.labels, window.latest.TotalReservedQuantity)
+
+		if window.avgCount > 0 {
+			reservationWindowAvgUsage.Add(window.labels, window.avgUsage/window.avgCount)
+		}
+		reservationWindowMinUsage.AddIfNotNil(window.labels, window.minUsage)
+		reservationWindowMaxUsage.AddIfNotNil(window.labels, window.maxUsage)
+		reservationWindowUsedHours.Add(window.labels, window.usedHours)
+		reservationWindowReservedHours.Add(window.labels, window.totalHours)
+	}
+}
+
+// setupReservationDetails registers reservation usage metrics per consuming resource
+func (m *MetricsCollectorAzureRmReservation) setupReservationDetails() {
+	labels := []string{
+		"scope",
+		"reservationOrderID",
+		"reservationID",
+		"skuName",
+		"kind",
+		"resourceID",
+		"subscriptionID",
+		"resourceGroup",
+		"instanceFlexibilityGroup",
+		"location",
+	}
+
+	m.Collector.RegisterMetricList(
+		"reservationResourceUsedHours",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_resource_used_hours",
+				Help: "Azure ResourceManager Reservation Used Hours per consuming resource over fromDays",
+			},
+			labels,
+		),
+		true,
+	)
+
+	m.Collector.RegisterMetricList(
+		"reservationResourceLastUsage",
+		prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_reservation_resource_last_usage_timestamp",
+				Help: "Azure ResourceManager Reservation last usage date per consuming resource",
+			},
+			labels,
+		),
+		true,
+	)
+}
+
+// collectReservationDetails collects reservation usage per consuming resource aggregated over fromDays
+func (m *MetricsCollectorAzureRmReservation) collectReservationDetails(logger *zap.SugaredLogger, scope string) {
+	reservationResourceUsedHours := m.Collector.GetMetricList("reservationResourceUsedHours")
+	reservationResourceLastUsage := m.Collector.GetMetricList("reservationResourceLastUsage")
+
+	days := Config.Collectors.Reservation.FromDays
+
+	now := time.Now()
+	startDate := now.AddDate(0, 0, -days).Format("2006-01-02")
+	endDate := now.Format("2006-01-02")
+
+	client, err := armconsumption.NewReservationsDetailsClient(AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	logger.Info(`fetching reservation details`)
+	pager := client.NewListPager(scope, &armconsumption.ReservationsDetailsClientListOptions{
+		StartDate: to.Ptr(startDate),
+		EndDate:   to.Ptr(endDate),
+	})
+
+	usageList := map[string]*reservationResourceUsage{}
+	for pager.More() {
+		page, err := pager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		for _, detail := range page.Value {
+			properties := detail.Properties
+			if properties == nil {
+				continue
+			}
+
+			resourceId := to.StringLower(properties.InstanceID)
+			labels := prometheus.Labels{
+				"scope":                    scope,
+				"reservationOrderID":       to.String(properties.ReservationOrderID),
+				"reservationID":            to.String(properties.ReservationID),
+				"skuName":                  to.String(properties.SKUName),
+				"kind":                     to.String(properties.Kind),
+				"resourceID":               resourceId,
+				"subscriptionID":           "",
+				"resourceGroup":            "",
+				"instanceFlexibilityGroup": to.String(properties.InstanceFlexibilityGroup),
+				"location":                 "",
+			}
+
+			// reservation details don't contain the location, only available with inventory
+			if location, exists := m.reservationLocations[to.StringLower(properties.ReservationID)]; exists {
+				labels["location"] = location
+			}
+
+			if azureResource, err := armclient.ParseResourceId(resourceId); err == nil {
+				labels["subscriptionID"] = azureResource.Subscription
+				labels["resourceGroup"] = azureResource.ResourceGroup
+			}
+
+			key := prometheusLabelsKey(labels)
+			if _, exists := usageList[key]; !exists {
+				usageList[key] = &reservationResourceUsage{labels: labels}
+			}
+			usage := usageList[key]
+
+			usage.usedHours += to.Float64(properties.UsedHours)
+			if properties.UsageDate != nil && to.Float64(properties.UsedHours) > 0 {
+				if usage.lastUsageDate == nil || properties.UsageDate.After(*usage.lastUsageDate) {
+					usage.lastUsageDate = properties.UsageDate
+				}
+			}
+		}
+	}
+
+	for _, usage := range usageList {
+		reservationResourceUsedHours.Add(usage.labels, usage.usedHours)
+		if usage.lastUsageDate != nil {
+			reservationResourceLastUsage.AddTime(usage.labels, *usage.lastUsageDate)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/prometheus/collector"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+)
+
+const (
+	ResourceProviderStateRegistered = "registered"
+)
+
+type MetricsCollectorAzureRmResourceProvider struct {
+	collector.Processor
+
+	prometheus struct {
+		resourceProviderInfo       *prometheus.GaugeVec
+		resourceProviderRegistered *prometheus.GaugeVec
+		resourceProviderRequired   *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorAzureRmResourceProvider) Setup(collector *collector.Collector) {
+	m.Processor.Setup(collector)
+
+	m.prometheus.resourceProviderInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_resourceprovider_info",
+			Help: "Azure ResourceManager resource provider registration information",
+		},
+		[]string{
+			"subscriptionID",
+			"provider",
+			"registrationState",
+			"registrationPolicy",
+		},
+	)
+	m.Collector.RegisterMetricList("resourceProviderInfo", m.prometheus.resourceProviderInfo, true)
+
+	m.prometheus.resourceProviderRegistered = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_resourceprovider_registered",
+			Help: "Azure ResourceManager resource provider registration status (1: registered)",
+		},
+		[]string{
+			"subscriptionID",
+			"provider",
+		},
+	)
+	m.Collector.RegisterMetricList("resourceProviderRegistered", m.prometheus.resourceProviderRegistered, true)
+
+	if len(Config.Collectors.ResourceProvider.Required) > 0 {
+		m.prometheus.resourceProviderRequired = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_resourceprovider_required_missing",
+				Help: "Azure ResourceManager required resource provider is not registered (1: missing, 0: registered)",
+			},
+			[]string{
+				"subscriptionID",
+				"provider",
+				"registrationState",
+			},
+		)
+		m.Collector.RegisterMetricList("resourceProviderRequired", m.prometheus.resourceProviderRequired, true)
+	}
+}
+
+func (m *MetricsCollectorAzureRmResourceProvider) Reset() {}
+
+func (m *MetricsCollectorAzureRmResourceProvider) Collect(callback chan<- func()) {
+	err := AzureSubscriptionsIterator.ForEachAsync(m.Logger(), func(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+		m.collectResourceProviders(subscription, logger)
+	})
+	if err != nil {
+		m.Logger().Panic(err)
+	}
+}
+
+func (m *MetricsCollectorAzureRmResourceProvider) collectResourceProviders(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	infoMetric := m.Collector.GetMetricList("resourceProviderInfo")
+	registeredMetric := m.Collector.GetMetricList("resourceProviderRegistered")
+
+	client, err := armresources.NewProvidersClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	subscriptionId := to.StringLower(subscription.SubscriptionID)
+
+	// registration state per provider (key: lowercase namespace)
+	providerStates := map[string]string{}
+
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		result, err := pager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		for _, provider := range result.Value {
+			providerNamespace := to.String(provider.Namespace)
+			registrationState := to.String(provider.RegistrationState)
+			providerStates[strings.ToLower(providerNamespace)] = registrationState
+
+			infoMetric.AddInfo(prometheus.Labels{
+				"subscriptionID":     subscriptionId,
+				"provider":           providerNamespace,
+				"registrationState":  registrationState,
+				"registrationPolicy": to.String(provider.RegistrationPolicy),
+			})
+
+			registeredMetric.AddBool(prometheus.Labels{
+				"subscriptionID": subscriptionId,
+				"provider":       providerNamespace,
+			}, strings.EqualFold(registrationState, ResourceProviderStateRegistered))
+		}
+	}
+
+	if len(Config.Collectors.ResourceProvider.Required) > 0 {
+		requiredMetric := m.Collector.GetMetricList("resourceProviderRequired")
+		for _, providerNamespace := range Config.Collectors.ResourceProvider.Required {
+			registrationState, exists := providerStates[strings.ToLower(providerNamespace)]
+			if !exists {
+				registrationState = "Unknown"
+			}
+
+			requiredMetric.AddBool(prometheus.Labels{
+				"subscriptionID":    subscriptionId,
+				"provider":          providerNamespace,
+				"registrationState": registrationState,
+			}, !strings.EqualFold(registrationState, ResourceProviderStateRegistered))
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/webdevops/go-common/azuresdk/armclient"
@@ -9,12 +13,35 @@ import (
 	"go.uber.org/zap"
 )
 
+type (
+	resourceTagMap map[string]string
+)
+
 type MetricsCollectorAzureRmResources struct {
 	collector.Processor
 
 	prometheus struct {
 		resource      *prometheus.GaugeVec
 		resourceGroup *prometheus.GaugeVec
+		resourceCount *prometheus.GaugeVec
+
+		resourceSku         *prometheus.GaugeVec
+		resourceSkuCapacity *prometheus.GaugeVec
+		resourceDetails     *prometheus.GaugeVec
+		resourceIdentity    *prometheus.GaugeVec
+		resourceCreated     *prometheus.GaugeVec
+		resourceChanged     *prometheus.GaugeVec
+
+		resourceFirstSeen    *prometheus.GaugeVec
+		resourceCreatedCount *prometheus.GaugeVec
+		resourceDeletedCount *prometheus.GaugeVec
+	}
+
+	resourceCountTagManager *armclient.ResourceTagManager
+
+	lifecycle struct {
+		lock      sync.Mutex
+		inventory resourceInventory
 	}
 }
 
@@ -57,17 +84,39 @@ func (m *MetricsCollectorAzureRmResources) Setup(collector *collector.Collector)
 		),
 	)
 	m.Collector.RegisterMetricList("resourceGroup", m.prometheus.resourceGroup, true)
+
+	if Config.Collectors.Resource.Count.Enabled {
+		m.setupResourceCount()
+	}
+
+	if Config.Collectors.Resource.Details.Enabled {
+		m.setupResourceDetails()
+	}
+
+	if Config.Collectors.Resource.Lifecycle.Enabled {
+		m.setupResourceLifecycle()
+	}
 }
 
 func (m *MetricsCollectorAzureRmResources) Reset() {}
 
 func (m *MetricsCollectorAzureRmResources) Collect(callback chan<- func()) {
-	err := AzureSubscriptionsIterator.ForEachAsync(m.Logger(), func(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
-		m.collectAzureResourceGroup(subscription, logger, callback)
-		m.collectAzureResources(subscription, logger, callback)
-	})
-	if err != nil {
-		m.Logger().Panic(err)
+	m.lifecycle.inventory = resourceInventory{}
+
+	if Config.Collectors.Resource.IsResourceGraphBackend() {
+		m.collectResourceGraph(m.Logger())
+	} else {
+		err := AzureSubscriptionsIterator.ForEachAsync(m.Logger(), func(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+			m.collectAzureResourceGroup(subscription, logger, callback)
+			m.collectAzureResources(subscription, logger, callback)
+		})
+		if err != nil {
+			m.Logger().Panic(err)
+		}
+	}
+
+	if Config.Collectors.Resource.Lifecycle.Enabled {
+		m.collectResourceLifecycle()
 	}
 }
 
@@ -98,12 +147,36 @@ func (m *MetricsCollectorAzureRmResources) collectAzureResourceGroup(subscriptio
 }
 
 func (m *MetricsCollectorAzureRmResources) collectAzureResources(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger, callback chan<- func()) {
-	list, err := AzureClient.ListResources(m.Context(), *subscription.SubscriptionID)
+	// provisioningState (resource count), createdTime and changedTime (timestamps) are only returned with $expand
+	useExpandedList := Config.Collectors.Resource.Count.Enabled || Config.Collectors.Resource.Details.Timestamps
+
+	var list []*armresources.GenericResourceExpanded
+	if useExpandedList {
+		expand := "provisioningState"
+		if Config.Collectors.Resource.Details.Timestamps {
+			expand += ",createdTime,changedTime"
+		}
+		list = m.listAzureResourcesExpanded(subscription, expand, logger)
+	} else {
+		// also updates resource cache of AzureClient (used by tag manager and other collectors)
+		result, err := AzureClient.ListResources(m.Context(), *subscription.SubscriptionID)
+		if err != nil {
+			logger.Panic(err)
+		}
+		for _, resource := range result {
+			list = append(list, resource)
+		}
+	}
+
+	// resourcegroup tags are needed for tag inheritance of resources
+	resourceGroupList, err := AzureClient.ListCachedResourceGroups(m.Context(), *subscription.SubscriptionID)
 	if err != nil {
 		logger.Panic(err)
 	}
+	subscriptionTags := newResourceTagMap(subscription.Tags)
 
 	resourceMetric := m.Collector.GetMetricList("resource")
+	resourceCount := newResourceCountList()
 
 	for _, resource := range list {
 		resourceId := to.String(resource.ID)
@@ -119,7 +192,148 @@ func (m *MetricsCollectorAzureRmResources) collectAzureResources(subscription *a
 			"location":          to.StringLower(resource.Location),
 			"provisioningState": to.StringLower(resource.ProvisioningState),
 		}
-		infoLabels = AzureResourceTagManager.AddResourceTagsToPrometheusLabels(m.Context(), infoLabels, resourceId)
-		resourceMetric.AddInfo(infoLabels)
+
+		resourceTags := newResourceTagMap(resource.Tags)
+		resourceGroupTags := resourceTagMap{}
+		if resourceGroup, exists := resourceGroupList[azureResource.ResourceGroup]; exists {
+			resourceGroupTags = newResourceTagMap(resourceGroup.Tags)
+		}
+
+		if Config.Collectors.Resource.Lifecycle.Enabled {
+			m.trackResource(infoLabels["resourceID"], azureResource.Subscription, azureResource.ResourceType, resourceTags)
+		}
+
+		if Config.Collectors.Resource.Count.Enabled {
+			countLabels := resourceTagsToPrometheusLabels(m.resourceCountTagManager, resourceCountLabels(infoLabels), azureResource, resourceTags, resourceGroupTags, subscriptionTags)
+			resourceCount.Inc(countLabels)
+		}
+
+		if resourceInfoEnabled(infoLabels["resourceID"]) {
+			if useExpandedList {
+				// resource is not in resource cache of AzureClient, resolve tags from fetched resource
+				infoLabels = resourceTagsToPrometheusLabels(AzureResourceTagManager, infoLabels, azureResource, resourceTags, resourceGroupTags, subscriptionTags)
+			} else {
+				infoLabels = AzureResourceTagManager.AddResourceTagsToPrometheusLabels(m.Context(), infoLabels, resourceId)
+			}
+			resourceMetric.AddInfo(infoLabels)
+
+			if Config.Collectors.Resource.Details.Enabled {
+				m.addResourceDetails(infoLabels["resourceID"], newResourceDetailsFromArm(resource))
+			}
+		}
+	}
+
+	if Config.Collectors.Resource.Count.Enabled {
+		m.exportResourceCount(resourceCount)
+	}
+}
+
+// listAzureResourcesExpanded lists resources of subscription with $expand
+func (m *MetricsCollectorAzureRmResources) listAzureResourcesExpanded(subscription *armsubscriptions.Subscription, expand string, logger *zap.SugaredLogger) (list []*armresources.GenericResourceExpanded) {
+	client, err := armresources.NewClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	pager := client.NewListPager(&armresources.ClientListOptions{
+		Expand: to.Ptr(expand),
+	})
+	for pager.More() {
+		result, err := pager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+		list = append(list, result.Value...)
+	}
+
+	return
+}
+
+func newResourceTagMap(tags map[string]*string) resourceTagMap {
+	ret := resourceTagMap{}
+	for tagName, tagValue := range tags {
+		ret[tagName] = to.String(tagValue)
+	}
+	return ret
+}
+
+// Get returns the tag value by case-insensitive tag name
+func (tags resourceTagMap) Get(tagName string) string {
+	if val, exists := tags[tagName]; exists {
+		return strings.TrimSpace(val)
+	}
+
+	for name, val := range tags {
+		if strings.EqualFold(name, tagName) {
+			return strings.TrimSpace(val)
+		}
 	}
+	return ""
+}
+
+// resourceTagsToPrometheusLabels resolves the configured tags from already fetched resource, resourcegroup and subscription tags,
+// same behaviour as ResourceTagManager.AddResourceTagsToPrometheusLabels but without additional ARM requests
+func resourceTagsToPrometheusLabels(tagManager *armclient.ResourceTagManager, labels prometheus.Labels, azureResource *armclient.AzureResourceInfo, resourceTags, resourceGroupTags, subscriptionTags resourceTagMap) prometheus.Labels {
+	fetchTagValue := func(tagName, tagSource string) string {
+		switch tagSource {
+		case armclient.AzureTagSourceResource:
+			return strings.TrimSpace(resourceTags[tagName])
+		case armclient.AzureTagSourceResourceGroup:
+			return strings.TrimSpace(resourceGroupTags[tagName])
+		case armclient.AzureTagSourceSubscription:
+			return strings.TrimSpace(subscriptionTags[tagName])
+		}
+		return ""
+	}
+
+	for _, tagConfig := range tagManager.Tags {
+		tagSource := tagConfig.Source
+		if tagSource == "" {
+			tagSource = armclient.AzureTagSourceResource
+			if azureResource.ResourceName == "" {
+				tagSource = armclient.AzureTagSourceResourceGroup
+			}
+			if azureResource.ResourceGroup == "" {
+				tagSource = armclient.AzureTagSourceSubscription
+			}
+		}
+
+		tagValue := ""
+		switch tagSource {
+		case armclient.AzureTagSourceResource:
+			if azureResource.ResourceName != "" {
+				tagValue = fetchTagValue(tagConfig.Name, tagSource)
+			}
+		case armclient.AzureTagSourceResourceGroup:
+			if azureResource.ResourceGroup != "" {
+				tagValue = fetchTagValue(tagConfig.Name, tagSource)
+			}
+		case armclient.AzureTagSourceSubscription:
+			if azureResource.Subscription != "" {
+				tagValue = fetchTagValue(tagConfig.Name, tagSource)
+			}
+		}
+
+		if tagConfig.Inherit {
+			if tagValue == "" {
+				tagValue = fetchTagValue(tagConfig.Name, armclient.AzureTagSourceResourceGroup)
+			}
+
+			if tagValue == "" {
+				tagValue = fetchTagValue(tagConfig.Name, armclient.AzureTagSourceSubscription)
+			}
+		}
+
+		if tagConfig.Transform.ToLower {
+			tagValue = strings.ToLower(tagValue)
+		}
+
+		if tagConfig.Transform.ToUpper {
+			tagValue = strings.ToUpper(tagValue)
+		}
+
+		labels[tagConfig.TargetName] = tagValue
+	}
+
+	return labels
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"hash/fnv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type (
+	resourceCountList struct {
+		rows map[string]*resourceCountRow
+	}
+
+	resourceCountRow struct {
+		labels prometheus.Labels
+		count  float64
+	}
+)
+
+func (m *MetricsCollectorAzureRmResources) setupResourceCount() {
+	var err error
+	m.resourceCountTagManager, err = AzureClient.TagManager.ParseTagConfig(Config.Collectors.Resource.Count.Tags)
+	if err != nil {
+		m.Logger().Panic(err)
+	}
+
+	m.prometheus.resourceCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_resource_count",
+			Help: "Azure Resource count per subscription, type, location and provisioningState",
+		},
+		m.resourceCountTagManager.AddToPrometheusLabels(
+			[]string{
+				"subscriptionID",
+				"resourceType",
+				"location",
+				"provisioningState",
+			},
+		),
+	)
+	m.Collector.RegisterMetricList("resourceCount", m.prometheus.resourceCount, true)
+}
+
+func newResourceCountList() *resourceCountList {
+	return &resourceCountList{
+		rows: map[string]*resourceCountRow{},
+	}
+}
+
+// Inc increments the counter of the label set
+func (l *resourceCountList) Inc(labels prometheus.Labels) {
+	key := prometheusLabelsKey(labels)
+	if _, exists := l.rows[key]; !exists {
+		l.rows[key] = &resourceCountRow{labels: labels}
+	}
+	l.rows[key].count++
+}
+
+func (m *MetricsCollectorAzureRmResources) exportResourceCount(list *resourceCountList) {
+	countMetric := m.Collector.GetMetricList("resourceCount")
+	for _, row := range list.rows {
+		countMetric.Add(row.labels, row.count)
+	}
+}
+
+// resourceCountLabels returns the base labels of azurerm_resource_count from resource info labels
+func resourceCountLabels(infoLabels prometheus.Labels) prometheus.Labels {
+	return prometheus.Labels{
+		"subscriptionID":    infoLabels["subscriptionID"],
+		"resourceType":      infoLabels["resourceType"],
+		"location":          infoLabels["location"],
+		"provisioningState": infoLabels["provisioningState"],
+	}
+}
+
+// resourceInfoEnabled checks if azurerm_resource_info should be exported for this resource,
+// sampling is based on a hash of the resourceID so the same resources are exported on every run
+func resourceInfoEnabled(resourceId string) bool {
+	if !Config.Collectors.Resource.Info.IsEnabled() {
+		return false
+	}
+
+	sampleRatio := Config.Collectors.Resource.Info.GetSampleRatio()
+	if sampleRatio >= 1 {
+		return true
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(resourceId))
+	return float64(hash.Sum32()%10000) < sampleRatio*10000
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/utils/to"
+)
+
+type (
+	resourceDetails struct {
+		skuName     string
+		skuTier     string
+		skuCapacity *float64
+
+		kind      string
+		managedBy string
+		zones     []string
+
+		identityType string
+		identities   []resourceIdentity
+
+		createdTime *time.Time
+		changedTime *time.Time
+	}
+
+	resourceIdentity struct {
+		identityType       string
+		principalID        string
+		identityResourceID string
+	}
+)
+
+func (m *MetricsCollectorAzureRmResources) setupResourceDetails() {
+	m.prometheus.resourceSku = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_resource_sku_info",
+			Help: "Azure Resource SKU information",
+		},
+		[]string{
+			"resourceID",
+			"skuName",
+			"skuTier",
+		},
+	)
+	m.Collector.RegisterMetricList("resourceSku", m.prometheus.resourceSku, true)
+
+	m.prometheus.resourceSkuCapacity = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_resource_sku_capacity",
+			Help: "Azure Resource SKU capacity",
+		},
+		[]string{
+			"resourceID",
+		},
+	)
+	m.Collector.RegisterMetricList("resourceSkuCapacity", m.prometheus.resourceSkuCapacity, true)
+
+	m.prometheus.resourceDetails = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_resource_details_info",
+			Help: "Azure Resource details (kind, managedBy, zones, identity)",
+		},
+		[]string{
+			"resourceID",
+			"kind",
+			"managedBy",
+			"zones",
+			"identityType",
+		},
+	)
+	m.Collector.RegisterMetricList("resourceDetails", m.prometheus.resourceDetails, true)
+
+	m.prometheus.resourceIdentity = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_resource_identity_info",
+			Help: "Azure Resource managed identity information",
+		},
+		[]string{
+			"resourceID",
+			"identityType",
+			"principalID",
+			"identityResourceID",
+		},
+	)
+	m.Collector.RegisterMetricList("resourceIdentity", m.prometheus.resourceIdentity, true)
+
+	if Config.Collectors.Resource.Details.Timestamps {
+		m.prometheus.resourceCreated = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_resource_created_timestamp",
+				Help: "Azure Resource creation timestamp",
+			},
+			[]string{
+				"resourceID",
+			},
+		)
+		m.Collector.RegisterMetricList("resourceCreated", m.prometheus.resourceCreated, true)
+
+		m.prometheus.resourceChanged = prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "azurerm_resource_changed_timestamp",
+				Help: "Azure Resource last change timestamp",
+			},
+			[]string{
+				"resourceID",
+			},
+		)
+		m.Collector.RegisterMetricList("resourceChanged", m.prometheus.resourceChanged, true)
+	}
+}
+
+func (m *MetricsCollectorAzureRmResources) addResourceDetails(resourceId string, details resourceDetails) {
+	if details.skuName != "" || details.skuTier != "" {
+		m.Collector.GetMetricList("resourceSku").AddInfo(prometheus.Labels{
+			"resourceID": resourceId,
+			"skuName":    details.skuName,
+			"skuTier":    details.skuTier,
+		})
+	}
+
+	m.Collector.GetMetricList("resourceSkuCapacity").AddIfNotNil(prometheus.Labels{
+		"resourceID": resourceId,
+	}, details.skuCapacity)
+
+	m.Collector.GetMetricList("resourceDetails").AddInfo(prometheus.Labels{
+		"resourceID":   resourceId,
+		"kind":         details.kind,
+		"managedBy":    details.managedBy,
+		"zones":        strings.Join(details.zones, ","),
+		"identityType": details.identityType,
+	})
+
+	identityMetric := m.Collector.GetMetricList("resourceIdentity")
+	for _, identity := range details.identities {
+		identityMetric.AddInfo(prometheus.Labels{
+			"resourceID":         resourceId,
+			"identityType":       identity.identityType,
+			"principalID":        identity.principalID,
+			"identityResourceID": identity.identityResourceID,
+		})
+	}
+
+	if Config.Collectors.Resource.Details.Timestamps {
+		if details.createdTime != nil {
+			m.Collector.GetMetricList("resourceCreated").AddTime(prometheus.Labels{"resourceID": resourceId}, *details.createdTime)
+		}
+
+		if details.changedTime != nil {
+			m.Collector.GetMetricList("resourceChanged").AddTime(prometheus.Labels{"resourceID": resourceId}, *details.changedTime)
+		}
+	}
+}
+
+// newResourceDetailsFromArm builds resource details from ResourceManager generic resource
+func newResourceDetailsFromArm(resource *armresources.GenericResourceExpanded) resourceDetails {
+	details := resourceDetails{
+		kind:        to.StringLower(resource.Kind),
+		managedBy:   to.StringLower(resource.ManagedBy),
+		createdTime: resource.CreatedTime,
+		changedTime: resource.ChangedTime,
+	}
+
+	if resource.SKU != nil {
+		details.skuName = to.String(resource.SKU.Name)
+		details.skuTier = to.String(resource.SKU.Tier)
+		if resource.SKU.Capacity != nil {
+			details.skuCapacity = to.Ptr(float64(*resource.SKU.Capacity))
+		}
+	}
+
+	if resource.Identity != nil {
+		if resource.Identity.Type != nil {
+			details.identityType = string(*resource.Identity.Type)
+		}
+
+		if resource.Identity.PrincipalID != nil {
+			details.identities = append(details.identities, resourceIdentity{
+				identityType: "SystemAssigned",
+				principalID:  to.StringLower(resource.Identity.PrincipalID),
+			})
+		}
+
+		for identityResourceId, identity := range resource.Identity.UserAssignedIdentities {
+			userIdentity := resourceIdentity{
+				identityType:       "UserAssigned",
+				identityResourceID: stringToStringLower(identityResourceId),
+			}
+			if identity != nil {
+				userIdentity.principalID = to.StringLower(identity.PrincipalID)
+			}
+			details.identities = append(details.identities, userIdentity)
+		}
+	}
+
+	return details
+}
+
+// newResourceDetailsFromResourceGraph builds resource details from ResourceGraph result row
+func newResourceDetailsFromResourceGraph(row map[string]interface{}) resourceDetails {
+	details := resourceDetails{
+		kind:      stringToStringLower(resourceGraphString(row, "kind")),
+		managedBy: stringToStringLower(resourceGraphString(row, "managedBy")),
+	}
+
+	if sku, ok := row["sku"].(map[string]interface{}); ok {
+		details.skuName = resourceGraphString(sku, "name")
+		details.skuTier = resourceGraphString(sku, "tier")
+		if capacity, ok := sku["capacity"].(float64); ok {
+			details.skuCapacity = to.Ptr(capacity)
+		}
+	}
+
+	if zones, ok := row["zones"].([]interface{}); ok {
+		for _, zone := range zones {
+			if val, ok := zone.(string); ok {
+				details.zones = append(details.zones, val)
+			}
+		}
+		sort.Strings(details.zones)
+	}
+
+	if identity, ok := row["identity"].(map[string]interface{}); ok {
+		details.identityType = resourceGraphString(identity, "type")
+
+		if principalId := resourceGraphString(identity, "principalId"); principalId != "" {
+			details.identities = append(details.identities, resourceIdentity{
+				identityType: "SystemAssigned",
+				principalID:  stringToStringLower(principalId),
+			})
+		}
+
+		if userIdentities, ok := identity["userAssignedIdentities"].(map[string]interface{}); ok {
+			for identityResourceId, userIdentityRow := range userIdentities {
+				userIdentity := resourceIdentity{
+					identityType:       "UserAssigned",
+					identityResourceID: stringToStringLower(identityResourceId),
+				}
+				if val, ok := userIdentityRow.(map[string]interface{}); ok {
+					userIdentity.principalID = stringToStringLower(resourceGraphString(val, "principalId"))
+				}
+				details.identities = append(details.identities, userIdentity)
+			}
+		}
+	}
+
+	return details
+}
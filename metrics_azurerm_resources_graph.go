@@ -0,0 +1,214 @@
+package main
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/azuresdk/armclient"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+)
+
+const (
+	ResourceGraphQueryResourceGroups = `resourcecontainers
+| where type =~ "microsoft.resources/subscriptions/resourcegroups"
+| project id, location, tags, provisioningState = tostring(properties.provisioningState)`
+
+	ResourceGraphQueryResources = `resources
+| project id, location, tags, provisioningState = tostring(properties.provisioningState)`
+
+	ResourceGraphQueryResourcesDetails = `resources
+| project id, location, tags, provisioningState = tostring(properties.provisioningState), sku, kind, managedBy, identity, zones`
+)
+
+// Collect Azure ResourceGroup and Resource metrics using Azure ResourceGraph
+func (m *MetricsCollectorAzureRmResources) collectResourceGraph(logger *zap.SugaredLogger) {
+	subscriptionList, err := AzureSubscriptionsIterator.ListSubscriptions()
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	subscriptionTags := map[string]resourceTagMap{}
+	subscriptionIdList := []string{}
+	for _, subscription := range subscriptionList {
+		subscriptionId := to.StringLower(subscription.SubscriptionID)
+		subscriptionIdList = append(subscriptionIdList, subscriptionId)
+
+		subscriptionTags[subscriptionId] = newResourceTagMap(subscription.Tags)
+	}
+
+	batchSize := Config.Collectors.Resource.ResourceGraph.GetBatchSize()
+	for len(subscriptionIdList) > 0 {
+		batch := subscriptionIdList
+		if len(batch) > batchSize {
+			batch = subscriptionIdList[:batchSize]
+		}
+		subscriptionIdList = subscriptionIdList[len(batch):]
+
+		m.collectResourceGraphBatch(logger.With(zap.Int("subscriptions", len(batch))), batch, subscriptionTags)
+	}
+}
+
+func (m *MetricsCollectorAzureRmResources) collectResourceGraphBatch(logger *zap.SugaredLogger, subscriptions []string, subscriptionTags map[string]resourceTagMap) {
+	resourceGroupMetric := m.Collector.GetMetricList("resourceGroup")
+	resourceMetric := m.Collector.GetMetricList("resource")
+	resourceCount := newResourceCountList()
+
+	// resourcegroup tags are needed for tag inheritance of resources
+	resourceGroupTags := map[string]resourceTagMap{}
+
+	logger.Debug("fetching resourcegroups via resourcegraph")
+	for _, row := range m.queryResourceGraph(logger, subscriptions, ResourceGraphQueryResourceGroups) {
+		resourceId := resourceGraphString(row, "id")
+		azureResource, err := armclient.ParseResourceId(resourceId)
+		if err != nil {
+			logger.Warnf(`unable to parse resourceID "%v": %v`, resourceId, err.Error())
+			continue
+		}
+
+		tags := resourceGraphTagsFromRow(row)
+		resourceGroupTags[azureResource.Subscription+"/"+azureResource.ResourceGroup] = tags
+
+		infoLabels := prometheus.Labels{
+			"resourceID":        stringToStringLower(resourceId),
+			"subscriptionID":    azureResource.Subscription,
+			"resourceGroup":     azureResource.ResourceGroup,
+			"location":          stringToStringLower(resourceGraphString(row, "location")),
+			"provisioningState": stringToStringLower(resourceGraphString(row, "provisioningState")),
+		}
+		infoLabels = resourceTagsToPrometheusLabels(
+			AzureResourceGroupTagManager,
+			infoLabels,
+			azureResource,
+			nil,
+			tags,
+			subscriptionTags[azureResource.Subscription],
+		)
+		resourceGroupMetric.AddInfo(infoLabels)
+	}
+
+	resourceQuery := ResourceGraphQueryResources
+	if Config.Collectors.Resource.Details.Enabled {
+		resourceQuery = ResourceGraphQueryResourcesDetails
+	}
+
+	logger.Debug("fetching resources via resourcegraph")
+	for _, row := range m.queryResourceGraph(logger, subscriptions, resourceQuery) {
+		resourceId := resourceGraphString(row, "id")
+		azureResource, err := armclient.ParseResourceId(resourceId)
+		if err != nil {
+			logger.Warnf(`unable to parse resourceID "%v": %v`, resourceId, err.Error())
+			continue
+		}
+
+		infoLabels := prometheus.Labels{
+			"subscriptionID":    azureResource.Subscription,
+			"resourceID":        stringToStringLower(resourceId),
+			"resourceName":      azureResource.ResourceName,
+			"resourceGroup":     azureResource.ResourceGroup,
+			"provider":          azureResource.ResourceProviderName,
+			"resourceType":      azureResource.ResourceType,
+			"location":          stringToStringLower(resourceGraphString(row, "location")),
+			"provisioningState": stringToStringLower(resourceGraphString(row, "provisioningState")),
+		}
+		tags := resourceGraphTagsFromRow(row)
+
+		if Config.Collectors.Resource.Lifecycle.Enabled {
+			m.trackResource(infoLabels["resourceID"], azureResource.Subscription, azureResource.ResourceType, tags)
+		}
+
+		if Config.Collectors.Resource.Count.Enabled {
+			countLabels := resourceTagsToPrometheusLabels(
+				m.resourceCountTagManager,
+				resourceCountLabels(infoLabels),
+				azureResource,
+				tags,
+				resourceGroupTags[azureResource.Subscription+"/"+azureResource.ResourceGroup],
+				subscriptionTags[azureResource.Subscription],
+			)
+			resourceCount.Inc(countLabels)
+		}
+
+		if resourceInfoEnabled(infoLabels["resourceID"]) {
+			infoLabels = resourceTagsToPrometheusLabels(
+				AzureResourceTagManager,
+				infoLabels,
+				azureResource,
+				tags,
+				resourceGroupTags[azureResource.Subscription+"/"+azureResource.ResourceGroup],
+				subscriptionTags[azureResource.Subscription],
+			)
+			resourceMetric.AddInfo(infoLabels)
+
+			if Config.Collectors.Resource.Details.Enabled {
+				m.addResourceDetails(infoLabels["resourceID"], newResourceDetailsFromResourceGraph(row))
+			}
+		}
+	}
+
+	if Config.Collectors.Resource.Count.Enabled {
+		m.exportResourceCount(resourceCount)
+	}
+}
+
+// queryResourceGraph executes the query for the subscriptions and follows the skipToken until all rows are fetched
+func (m *MetricsCollectorAzureRmResources) queryResourceGraph(logger *zap.SugaredLogger, subscriptions []string, query string) []map[string]interface{} {
+	list := []map[string]interface{}{}
+
+	client, err := armresourcegraph.NewClient(AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	resultFormat := armresourcegraph.ResultFormatObjectArray
+	requestOptions := armresourcegraph.QueryRequestOptions{
+		ResultFormat: &resultFormat,
+		Top:          to.Ptr(int32(armclient.ResourceGraphQueryOptionsTop)),
+	}
+
+	for {
+		request := armresourcegraph.QueryRequest{
+			Subscriptions: to.SlicePtr(subscriptions),
+			Query:         to.Ptr(query),
+			Options:       &requestOptions,
+		}
+
+		result, err := client.Resources(m.Context(), request, nil)
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		if rowList, ok := result.Data.([]interface{}); ok {
+			for _, row := range rowList {
+				if rowData, ok := row.(map[string]interface{}); ok {
+					list = append(list, rowData)
+				}
+			}
+		}
+
+		if result.SkipToken == nil || *result.SkipToken == "" {
+			break
+		}
+		requestOptions.SkipToken = result.SkipToken
+	}
+
+	return list
+}
+
+func resourceGraphTagsFromRow(row map[string]interface{}) resourceTagMap {
+	tags := resourceTagMap{}
+	if rowTags, ok := row["tags"].(map[string]interface{}); ok {
+		for tagName, tagValue := range rowTags {
+			if val, ok := tagValue.(string); ok {
+				tags[tagName] = val
+			}
+		}
+	}
+	return tags
+}
+
+func resourceGraphString(row map[string]interface{}, field string) string {
+	if val, ok := row[field].(string); ok {
+		return val
+	}
+	return ""
+}
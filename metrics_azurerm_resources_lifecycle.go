@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	ResourceInventoryDataName = "resourceInventory"
+
+	ResourceChangeAdded       = "added"
+	ResourceChangeRemoved     = "removed"
+	ResourceChangeTagsChanged = "tagsChanged"
+)
+
+type (
+	// resourceInventory contains all known resources (key: resourceID)
+	resourceInventory map[string]*resourceInventoryEntry
+
+	resourceInventoryEntry struct {
+		SubscriptionID string         `json:"subscriptionID"`
+		ResourceType   string         `json:"resourceType"`
+		FirstSeen      int64          `json:"firstSeen"`
+		Tags           resourceTagMap `json:"tags"`
+	}
+
+	resourceChangeLogEntry struct {
+		Timestamp      string         `json:"timestamp"`
+		Action         string         `json:"action"`
+		ResourceID     string         `json:"resourceID"`
+		SubscriptionID string         `json:"subscriptionID"`
+		ResourceType   string         `json:"resourceType"`
+		Tags           resourceTagMap `json:"tags,omitempty"`
+		PreviousTags   resourceTagMap `json:"previousTags,omitempty"`
+	}
+)
+
+func (m *MetricsCollectorAzureRmResources) setupResourceLifecycle() {
+	m.prometheus.resourceFirstSeen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_resource_first_seen_timestamp",
+			Help: "Azure Resource timestamp when resource was first seen by the exporter",
+		},
+		[]string{
+			"resourceID",
+			"subscriptionID",
+			"resourceType",
+		},
+	)
+	m.Collector.RegisterMetricList("resourceFirstSeen", m.prometheus.resourceFirstSeen, true)
+
+	m.prometheus.resourceCreatedCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_resource_created_count",
+			Help: "Azure Resource count of resources created since last run",
+		},
+		[]string{
+			"subscriptionID",
+			"resourceType",
+		},
+	)
+	m.Collector.RegisterMetricList("resourceCreatedCount", m.prometheus.resourceCreatedCount, true)
+
+	m.prometheus.resourceDeletedCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_resource_deleted_count",
+			Help: "Azure Resource count of resources deleted since last run",
+		},
+		[]string{
+			"subscriptionID",
+			"resourceType",
+		},
+	)
+	m.Collector.RegisterMetricList("resourceDeletedCount", m.prometheus.resourceDeletedCount, true)
+}
+
+// trackResource adds resource to current inventory (called concurrently from subscription iterator)
+func (m *MetricsCollectorAzureRmResources) trackResource(resourceId, subscriptionId, resourceType string, tags resourceTagMap) {
+	m.lifecycle.lock.Lock()
+	defer m.lifecycle.lock.Unlock()
+
+	m.lifecycle.inventory[resourceId] = &resourceInventoryEntry{
+		SubscriptionID: subscriptionId,
+		ResourceType:   resourceType,
+		Tags:           tags,
+	}
+}
+
+// collectResourceLifecycle compares current with previous inventory and exports first seen, created and deleted resources
+func (m *MetricsCollectorAzureRmResources) collectResourceLifecycle() {
+	firstSeenMetric := m.Collector.GetMetricList("resourceFirstSeen")
+	createdCount := newResourceCountList()
+	deletedCount := newResourceCountList()
+
+	now := time.Now()
+	previousInventory, restored := m.resourceInventory()
+	currentInventory := m.lifecycle.inventory
+	changeLog := []resourceChangeLogEntry{}
+
+	for resourceId, entry := range currentInventory {
+		countLabels := prometheus.Labels{
+			"subscriptionID": entry.SubscriptionID,
+			"resourceType":   entry.ResourceType,
+		}
+
+		if previousEntry, exists := previousInventory[resourceId]; exists {
+			entry.FirstSeen = previousEntry.FirstSeen
+
+			if !reflect.DeepEqual(entry.Tags, previousEntry.Tags) && (len(entry.Tags) > 0 || len(previousEntry.Tags) > 0) {
+				changeLog = append(changeLog, resourceChangeLogEntry{
+					Action:       ResourceChangeTagsChanged,
+					ResourceID:   resourceId,
+					Tags:         entry.Tags,
+					PreviousTags: previousEntry.Tags,
+				})
+			}
+		} else {
+			entry.FirstSeen = now.Unix()
+
+			// on first run all resources are new, only track changes if previous inventory is known
+			if restored {
+				createdCount.Inc(countLabels)
+				changeLog = append(changeLog, resourceChangeLogEntry{
+					Action:     ResourceChangeAdded,
+					ResourceID: resourceId,
+					Tags:       entry.Tags,
+				})
+			}
+		}
+
+		firstSeenMetric.Add(prometheus.Labels{
+			"resourceID":     resourceId,
+			"subscriptionID": entry.SubscriptionID,
+			"resourceType":   entry.ResourceType,
+		}, float64(entry.FirstSeen))
+	}
+
+	for resourceId, previousEntry := range previousInventory {
+		if _, exists := currentInventory[resourceId]; !exists {
+			deletedCount.Inc(prometheus.Labels{
+				"subscriptionID": previousEntry.SubscriptionID,
+				"resourceType":   previousEntry.ResourceType,
+			})
+			changeLog = append(changeLog, resourceChangeLogEntry{
+				Action:       ResourceChangeRemoved,
+				ResourceID:   resourceId,
+				PreviousTags: previousEntry.Tags,
+			})
+		}
+	}
+
+	createdMetric := m.Collector.GetMetricList("resourceCreatedCount")
+	for _, row := range createdCount.rows {
+		createdMetric.Add(row.labels, row.count)
+	}
+
+	deletedMetric := m.Collector.GetMetricList("resourceDeletedCount")
+	for _, row := range deletedCount.rows {
+		deletedMetric.Add(row.labels, row.count)
+	}
+
+	if Config.Collectors.Resource.Lifecycle.ChangeLog != nil && len(changeLog) > 0 {
+		for i := range changeLog {
+			entry := &changeLog[i]
+			entry.Timestamp = now.Format(time.RFC3339)
+			if val, exists := currentInventory[entry.ResourceID]; exists {
+				entry.SubscriptionID = val.SubscriptionID
+				entry.ResourceType = val.ResourceType
+			} else if val, exists := previousInventory[entry.ResourceID]; exists {
+				entry.SubscriptionID = val.SubscriptionID
+				entry.ResourceType = val.ResourceType
+			}
+		}
+		m.writeResourceChangeLog(*Config.Collectors.Resource.Lifecycle.ChangeLog, changeLog)
+	}
+
+	m.Collector.SetData(ResourceInventoryDataName, currentInventory)
+}
+
+// writeResourceChangeLog appends changes as JSON lines to change log file
+func (m *MetricsCollectorAzureRmResources) writeResourceChangeLog(path string, changeLog []resourceChangeLogEntry) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) // #nosec G302 G304 change log is meant to be read by other tools
+	if err != nil {
+		m.Logger().Errorf(`unable to open resource change log "%v": %v`, path, err.Error())
+		return
+	}
+	defer file.Close() // nolint:errcheck
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range changeLog {
+		if err := encoder.Encode(entry); err != nil {
+			m.Logger().Errorf(`unable to write resource change log "%v": %v`, path, err.Error())
+			return
+		}
+	}
+}
+
+// resourceInventory returns previous inventory from collector data (or from cache file after restart)
+func (m *MetricsCollectorAzureRmResources) resourceInventory() (resourceInventory, bool) {
+	if inventory, ok := m.Collector.GetData(ResourceInventoryDataName).(resourceInventory); ok {
+		return inventory, true
+	}
+
+	inventory := resourceInventory{}
+	if restoreCollectorData(m.Collector, m.Logger(), ResourceInventoryDataName, &inventory, Config.Azure, Config.Collectors.Resource) {
+		m.Logger().Infof(`restored resource inventory of %v resources from cache`, len(inventory))
+		return inventory, true
+	}
+
+	return inventory, false
+}
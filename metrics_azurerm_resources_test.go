@@ -0,0 +1,170 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/azuresdk/armclient"
+	"github.com/webdevops/go-common/utils/to"
+)
+
+func TestResourceTagsToPrometheusLabels(t *testing.T) {
+	tagManager := &armclient.ResourceTagManager{
+		Tags: []armclient.ResourceTagConfigTag{
+			{Name: "owner", TargetName: "tag_owner"},
+			{Name: "owner", Source: armclient.AzureTagSourceResourceGroup, TargetName: "tag_rgowner"},
+			{Name: "owner", Source: armclient.AzureTagSourceSubscription, TargetName: "tag_subowner"},
+			{Name: "team", TargetName: "tag_team", Inherit: true},
+			{Name: "team", TargetName: "tag_teamlower", Inherit: true, Transform: armclient.ResourceTagConfigTransform{ToLower: true}},
+			{Name: "costcenter", TargetName: "tag_costcenter", Inherit: true, Transform: armclient.ResourceTagConfigTransform{ToUpper: true}},
+		},
+	}
+
+	subscriptionTags := resourceTagMap{"owner": "subscription-owner", "team": "Platform", "costcenter": "cc-1000"}
+	resourceGroupTags := resourceTagMap{"owner": "rg-owner", "team": " Backend "}
+
+	testCases := []struct {
+		name              string
+		resourceId        string
+		resourceTags      resourceTagMap
+		resourceGroupTags resourceTagMap
+		expected          prometheus.Labels
+	}{
+		{
+			name:              "resource with own tags",
+			resourceId:        "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg-tagged/providers/Microsoft.Storage/storageAccounts/tagged",
+			resourceTags:      resourceTagMap{"owner": "resource-owner", "team": "Frontend"},
+			resourceGroupTags: resourceGroupTags,
+			expected: prometheus.Labels{
+				"tag_owner": "resource-owner", "tag_rgowner": "rg-owner", "tag_subowner": "subscription-owner",
+				"tag_team": "Frontend", "tag_teamlower": "frontend", "tag_costcenter": "CC-1000",
+			},
+		},
+		{
+			name:              "resource inheriting empty tag from resourcegroup",
+			resourceId:        "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg-tagged/providers/Microsoft.Storage/storageAccounts/inherited",
+			resourceTags:      resourceTagMap{"team": ""},
+			resourceGroupTags: resourceGroupTags,
+			expected: prometheus.Labels{
+				"tag_owner": "", "tag_rgowner": "rg-owner", "tag_subowner": "subscription-owner",
+				"tag_team": "Backend", "tag_teamlower": "backend", "tag_costcenter": "CC-1000",
+			},
+		},
+		{
+			name:              "resource inheriting from subscription",
+			resourceId:        "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg-untagged/providers/Microsoft.Network/virtualNetworks/untagged",
+			resourceTags:      resourceTagMap{},
+			resourceGroupTags: resourceTagMap{},
+			expected: prometheus.Labels{
+				"tag_owner": "", "tag_rgowner": "", "tag_subowner": "subscription-owner",
+				"tag_team": "Platform", "tag_teamlower": "platform", "tag_costcenter": "CC-1000",
+			},
+		},
+		{
+			name:              "resourcegroup uses own tags",
+			resourceId:        "/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg-tagged",
+			resourceTags:      resourceTagMap{},
+			resourceGroupTags: resourceGroupTags,
+			expected: prometheus.Labels{
+				"tag_owner": "rg-owner", "tag_rgowner": "rg-owner", "tag_subowner": "subscription-owner",
+				"tag_team": "Backend", "tag_teamlower": "backend", "tag_costcenter": "CC-1000",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		azureResource, err := armclient.ParseResourceId(testCase.resourceId)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		labels := resourceTagsToPrometheusLabels(tagManager, prometheus.Labels{}, azureResource, testCase.resourceTags, testCase.resourceGroupTags, subscriptionTags)
+		assertPrometheusLabels(t, testCase.name, testCase.expected, labels)
+	}
+}
+
+func TestNewResourceDetailsFromArm(t *testing.T) {
+	createdTime := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	identityType := armresources.ResourceIdentityTypeSystemAssignedUserAssigned
+
+	details := newResourceDetailsFromArm(&armresources.GenericResourceExpanded{
+		Kind:        to.Ptr("StorageV2"),
+		ManagedBy:   to.Ptr("/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/RG-Managed"),
+		CreatedTime: &createdTime,
+		SKU: &armresources.SKU{
+			Name:     to.Ptr("Standard_LRS"),
+			Tier:     to.Ptr("Standard"),
+			Capacity: to.Ptr(int32(2)),
+		},
+		Identity: &armresources.Identity{
+			Type:        &identityType,
+			PrincipalID: to.Ptr("AAAAAAAA-0000-0000-0000-000000000001"),
+			UserAssignedIdentities: map[string]*armresources.IdentityUserAssignedIdentitiesValue{
+				"/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/RG-Identity/providers/Microsoft.ManagedIdentity/userAssignedIdentities/Identity": {
+					PrincipalID: to.Ptr("BBBBBBBB-0000-0000-0000-000000000002"),
+				},
+			},
+		},
+	})
+
+	if details.kind != "storagev2" || details.managedBy != "/subscriptions/00000000-0000-0000-0000-000000000001/resourcegroups/rg-managed" {
+		t.Errorf("unexpected kind/managedBy: %q, %q", details.kind, details.managedBy)
+	}
+
+	if details.skuName != "Standard_LRS" || details.skuTier != "Standard" || details.skuCapacity == nil || *details.skuCapacity != 2 {
+		t.Errorf("unexpected sku: %q, %q, %v", details.skuName, details.skuTier, details.skuCapacity)
+	}
+
+	if details.createdTime == nil || !details.createdTime.Equal(createdTime) || details.changedTime != nil {
+		t.Errorf("unexpected timestamps: %v, %v", details.createdTime, details.changedTime)
+	}
+
+	if details.identityType != string(identityType) {
+		t.Errorf("unexpected identity type: %q", details.identityType)
+	}
+
+	expectedIdentities := []resourceIdentity{
+		{identityType: "SystemAssigned", principalID: "aaaaaaaa-0000-0000-0000-000000000001"},
+		{
+			identityType:       "UserAssigned",
+			identityResourceID: "/subscriptions/00000000-0000-0000-0000-000000000001/resourcegroups/rg-identity/providers/microsoft.managedidentity/userassignedidentities/identity",
+			principalID:        "bbbbbbbb-0000-0000-0000-000000000002",
+		},
+	}
+	if len(details.identities) != len(expectedIdentities) {
+		t.Fatalf("expected identities %v, got %v", expectedIdentities, details.identities)
+	}
+	for num, identity := range expectedIdentities {
+		if details.identities[num] != identity {
+			t.Errorf("expected identity %v, got %v", identity, details.identities[num])
+		}
+	}
+
+	// ResourceManager list API doesn't return zones
+	if len(details.zones) != 0 {
+		t.Errorf("expected no zones, got %v", details.zones)
+	}
+
+	// resources without sku and identity
+	details = newResourceDetailsFromArm(&armresources.GenericResourceExpanded{})
+	if details.skuCapacity != nil || details.identityType != "" || len(details.identities) != 0 {
+		t.Errorf("expected empty details, got %+v", details)
+	}
+}
+
+func assertPrometheusLabels(t *testing.T, name string, expected, actual prometheus.Labels) {
+	t.Helper()
+
+	if len(expected) != len(actual) {
+		t.Errorf("%v: expected labels %v, got %v", name, expected, actual)
+		return
+	}
+
+	for labelName, value := range expected {
+		if actualValue, exists := actual[labelName]; !exists || actualValue != value {
+			t.Errorf("%v: expected label %v=%q, got %q", name, labelName, value, actualValue)
+		}
+	}
+}
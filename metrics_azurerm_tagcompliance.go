@@ -0,0 +1,256 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/azuresdk/armclient"
+	"github.com/webdevops/go-common/prometheus/collector"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+
+	"github.com/webdevops/azure-resourcemanager-exporter/config"
+)
+
+const (
+	TagComplianceStatusCompliant    = "compliant"
+	TagComplianceStatusInherited    = "inherited"
+	TagComplianceStatusMissing      = "missing"
+	TagComplianceStatusInvalidValue = "invalidValue"
+	TagComplianceStatusInvalidCase  = "invalidCase"
+
+	TagComplianceResourceTypeResourceGroup = "microsoft.resources/resourcegroups"
+)
+
+type (
+	tagComplianceRule struct {
+		config.CollectorTagComplianceRule
+		regex *regexp.Regexp
+	}
+)
+
+type MetricsCollectorAzureRmTagCompliance struct {
+	collector.Processor
+
+	prometheus struct {
+		tagCompliance        *prometheus.GaugeVec
+		tagComplianceSummary *prometheus.GaugeVec
+	}
+
+	rules             []tagComplianceRule
+	summaryTagManager *armclient.ResourceTagManager
+}
+
+func (m *MetricsCollectorAzureRmTagCompliance) Setup(collector *collector.Collector) {
+	var err error
+	m.Processor.Setup(collector)
+
+	for _, ruleConfig := range Config.Collectors.TagCompliance.Rules {
+		rule := tagComplianceRule{CollectorTagComplianceRule: ruleConfig}
+		if ruleConfig.Regex != nil {
+			rule.regex, err = regexp.Compile(*ruleConfig.Regex)
+			if err != nil {
+				m.Logger().Panicf(`unable to compile regex of tag compliance rule "%v": %v`, rule.GetName(), err.Error())
+			}
+		}
+		m.rules = append(m.rules, rule)
+	}
+
+	m.summaryTagManager, err = AzureClient.TagManager.ParseTagConfig(Config.Collectors.TagCompliance.SummaryTags)
+	if err != nil {
+		m.Logger().Panic(err)
+	}
+
+	m.prometheus.tagCompliance = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_tag_compliance",
+			Help: "Azure tag compliance status per resource and rule",
+		},
+		[]string{
+			"resourceID",
+			"subscriptionID",
+			"resourceGroup",
+			"resourceType",
+			"rule",
+			"status",
+		},
+	)
+	m.Collector.RegisterMetricList("tagCompliance", m.prometheus.tagCompliance, true)
+
+	m.prometheus.tagComplianceSummary = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_tag_compliance_summary",
+			Help: "Azure tag compliance resource count per subscription, rule and status",
+		},
+		m.summaryTagManager.AddToPrometheusLabels(
+			[]string{
+				"subscriptionID",
+				"rule",
+				"status",
+			},
+		),
+	)
+	m.Collector.RegisterMetricList("tagComplianceSummary", m.prometheus.tagComplianceSummary, true)
+}
+
+func (m *MetricsCollectorAzureRmTagCompliance) Reset() {}
+
+func (m *MetricsCollectorAzureRmTagCompliance) Collect(callback chan<- func()) {
+	err := AzureSubscriptionsIterator.ForEachAsync(m.Logger(), func(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+		m.collectTagCompliance(subscription, logger)
+	})
+	if err != nil {
+		m.Logger().Panic(err)
+	}
+}
+
+func (m *MetricsCollectorAzureRmTagCompliance) collectTagCompliance(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	complianceMetric := m.Collector.GetMetricList("tagCompliance")
+	summary := newResourceCountList()
+
+	subscriptionTags := newResourceTagMap(subscription.Tags)
+
+	resourceGroupList, err := AzureClient.ListResourceGroups(m.Context(), *subscription.SubscriptionID)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	evaluate := func(resourceId, resourceType string, azureResource *armclient.AzureResourceInfo, resourceTags, resourceGroupTags resourceTagMap) {
+		inheritedTags := resourceGroupTags
+		if azureResource.ResourceName == "" {
+			// resourcegroup: own tags are the resource tags, only inherited from subscription
+			inheritedTags = resourceTagMap{}
+		}
+
+		for _, rule := range m.rules {
+			if !rule.MatchesResourceType(resourceType) || !rule.MatchesScope(resourceId) {
+				continue
+			}
+
+			status := rule.evaluate(resourceTags, inheritedTags, subscriptionTags)
+			if status == "" {
+				continue
+			}
+
+			complianceMetric.AddInfo(prometheus.Labels{
+				"resourceID":     resourceId,
+				"subscriptionID": azureResource.Subscription,
+				"resourceGroup":  azureResource.ResourceGroup,
+				"resourceType":   resourceType,
+				"rule":           rule.GetName(),
+				"status":         status,
+			})
+
+			summaryLabels := prometheus.Labels{
+				"subscriptionID": azureResource.Subscription,
+				"rule":           rule.GetName(),
+				"status":         status,
+			}
+			summaryLabels = resourceTagsToPrometheusLabels(m.summaryTagManager, summaryLabels, azureResource, resourceTags, resourceGroupTags, subscriptionTags)
+			summary.Inc(summaryLabels)
+		}
+	}
+
+	// resourcegroups
+	for _, resourceGroup := range resourceGroupList {
+		resourceId := to.StringLower(resourceGroup.ID)
+		azureResource, err := armclient.ParseResourceId(resourceId)
+		if err != nil {
+			continue
+		}
+
+		resourceGroupTags := newResourceTagMap(resourceGroup.Tags)
+		evaluate(resourceId, TagComplianceResourceTypeResourceGroup, azureResource, resourceGroupTags, resourceGroupTags)
+	}
+
+	// resources
+	client, err := armresources.NewClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		result, err := pager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		for _, resource := range result.Value {
+			resourceId := to.StringLower(resource.ID)
+			azureResource, err := armclient.ParseResourceId(resourceId)
+			if err != nil {
+				continue
+			}
+
+			resourceGroupTags := resourceTagMap{}
+			if resourceGroup, exists := resourceGroupList[azureResource.ResourceGroup]; exists {
+				resourceGroupTags = newResourceTagMap(resourceGroup.Tags)
+			}
+
+			evaluate(resourceId, azureResource.ResourceType, azureResource, newResourceTagMap(resource.Tags), resourceGroupTags)
+		}
+	}
+
+	summaryMetric := m.Collector.GetMetricList("tagComplianceSummary")
+	for _, row := range summary.rows {
+		summaryMetric.Add(row.labels, row.count)
+	}
+}
+
+// evaluate returns compliance status of the rule for the tags, empty if rule is not applicable (tag not set and not required)
+func (rule *tagComplianceRule) evaluate(resourceTags, resourceGroupTags, subscriptionTags resourceTagMap) string {
+	status := TagComplianceStatusCompliant
+	tagValue := resourceTags.Get(rule.Tag)
+
+	if tagValue == "" && rule.Inherit {
+		status = TagComplianceStatusInherited
+		tagValue = resourceGroupTags.Get(rule.Tag)
+		if tagValue == "" {
+			tagValue = subscriptionTags.Get(rule.Tag)
+		}
+	}
+
+	if tagValue == "" {
+		if rule.Required {
+			return TagComplianceStatusMissing
+		}
+		return ""
+	}
+
+	if rule.Case != nil {
+		switch strings.ToLower(*rule.Case) {
+		case config.TagComplianceCaseLower:
+			if tagValue != strings.ToLower(tagValue) {
+				return TagComplianceStatusInvalidCase
+			}
+		case config.TagComplianceCaseUpper:
+			if tagValue != strings.ToUpper(tagValue) {
+				return TagComplianceStatusInvalidCase
+			}
+		}
+	}
+
+	if len(rule.AllowedValues) > 0 {
+		allowed := false
+		for _, val := range rule.AllowedValues {
+			if strings.EqualFold(val, tagValue) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return TagComplianceStatusInvalidValue
+		}
+	}
+
+	if rule.regex != nil && !rule.regex.MatchString(tagValue) {
+		return TagComplianceStatusInvalidValue
+	}
+
+	return status
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/webdevops/go-common/utils/to"
+
+	"github.com/webdevops/azure-resourcemanager-exporter/config"
+)
+
+func TestTagComplianceRuleEvaluate(t *testing.T) {
+	subscriptionTags := resourceTagMap{"owner": "subscription-owner", "env": "prod"}
+
+	tests := []struct {
+		name              string
+		rule              tagComplianceRule
+		resourceTags      resourceTagMap
+		resourceGroupTags resourceTagMap
+		expected          string
+	}{
+		{
+			name:         "compliant",
+			rule:         tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "owner", Required: true}},
+			resourceTags: resourceTagMap{"owner": "team-a"},
+			expected:     TagComplianceStatusCompliant,
+		},
+		{
+			name:         "tag name is case-insensitive",
+			rule:         tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "owner", Required: true}},
+			resourceTags: resourceTagMap{"Owner": "team-a"},
+			expected:     TagComplianceStatusCompliant,
+		},
+		{
+			name:              "missing",
+			rule:              tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "owner", Required: true}},
+			resourceTags:      resourceTagMap{},
+			resourceGroupTags: resourceTagMap{"owner": "rg-owner"},
+			expected:          TagComplianceStatusMissing,
+		},
+		{
+			name:         "whitespace only is missing",
+			rule:         tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "owner", Required: true}},
+			resourceTags: resourceTagMap{"owner": "  "},
+			expected:     TagComplianceStatusMissing,
+		},
+		{
+			name:         "not required and not set",
+			rule:         tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "costcenter", AllowedValues: []string{"a"}}},
+			resourceTags: resourceTagMap{},
+			expected:     "",
+		},
+		{
+			name:              "inherited from resourcegroup",
+			rule:              tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "owner", Required: true, Inherit: true}},
+			resourceTags:      resourceTagMap{},
+			resourceGroupTags: resourceTagMap{"owner": "rg-owner"},
+			expected:          TagComplianceStatusInherited,
+		},
+		{
+			name:              "inherited from subscription",
+			rule:              tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "env", Required: true, Inherit: true}},
+			resourceTags:      resourceTagMap{},
+			resourceGroupTags: resourceTagMap{},
+			expected:          TagComplianceStatusInherited,
+		},
+		{
+			name:              "inherited value is validated",
+			rule:              tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "env", Inherit: true, AllowedValues: []string{"dev", "test"}}},
+			resourceTags:      resourceTagMap{},
+			resourceGroupTags: resourceTagMap{},
+			expected:          TagComplianceStatusInvalidValue,
+		},
+		{
+			name:         "allowed values are case-insensitive",
+			rule:         tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "env", AllowedValues: []string{"prod", "dev"}}},
+			resourceTags: resourceTagMap{"env": "PROD"},
+			expected:     TagComplianceStatusCompliant,
+		},
+		{
+			name:         "invalid value",
+			rule:         tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "env", AllowedValues: []string{"prod", "dev"}}},
+			resourceTags: resourceTagMap{"env": "staging"},
+			expected:     TagComplianceStatusInvalidValue,
+		},
+		{
+			name:         "invalid case",
+			rule:         tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "env", Case: to.StringPtr(config.TagComplianceCaseLower), AllowedValues: []string{"prod"}}},
+			resourceTags: resourceTagMap{"env": "Prod"},
+			expected:     TagComplianceStatusInvalidCase,
+		},
+		{
+			name:         "upper case",
+			rule:         tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "costcenter", Case: to.StringPtr(config.TagComplianceCaseUpper)}},
+			resourceTags: resourceTagMap{"costcenter": "CC-1000"},
+			expected:     TagComplianceStatusCompliant,
+		},
+		{
+			name:         "regex",
+			rule:         tagComplianceRule{CollectorTagComplianceRule: config.CollectorTagComplianceRule{Tag: "costcenter"}, regex: regexp.MustCompile(`^CC-[0-9]+$`)},
+			resourceTags: resourceTagMap{"costcenter": "CC-X"},
+			expected:     TagComplianceStatusInvalidValue,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if status := test.rule.evaluate(test.resourceTags, test.resourceGroupTags, subscriptionTags); status != test.expected {
+				t.Errorf("expected status %q, got %q", test.expected, status)
+			}
+		})
+	}
+}
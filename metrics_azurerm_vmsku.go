@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/prometheus/collector"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+)
+
+type MetricsCollectorAzureRmVmSku struct {
+	collector.Processor
+
+	prometheus struct {
+		vmSkuInfo        *prometheus.GaugeVec
+		vmSkuAvailable   *prometheus.GaugeVec
+		vmSkuZone        *prometheus.GaugeVec
+		vmSkuRestriction *prometheus.GaugeVec
+		vmSkuCapability  *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorAzureRmVmSku) Setup(collector *collector.Collector) {
+	m.Processor.Setup(collector)
+
+	m.prometheus.vmSkuInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_vmsku_info",
+			Help: "Azure ResourceManager VM SKU information",
+		},
+		[]string{
+			"subscriptionID",
+			"location",
+			"vmSize",
+			"family",
+			"tier",
+			"size",
+		},
+	)
+	m.Collector.RegisterMetricList("vmSkuInfo", m.prometheus.vmSkuInfo, true)
+
+	m.prometheus.vmSkuAvailable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_vmsku_available",
+			Help: "Azure ResourceManager VM SKU availability in location (not restricted for subscription)",
+		},
+		[]string{
+			"subscriptionID",
+			"location",
+			"vmSize",
+		},
+	)
+	m.Collector.RegisterMetricList("vmSkuAvailable", m.prometheus.vmSkuAvailable, true)
+
+	m.prometheus.vmSkuZone = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_vmsku_zone_available",
+			Help: "Azure ResourceManager VM SKU availability in zone (supported zones, 0 if restricted)",
+		},
+		[]string{
+			"subscriptionID",
+			"location",
+			"vmSize",
+			"zone",
+		},
+	)
+	m.Collector.RegisterMetricList("vmSkuZone", m.prometheus.vmSkuZone, true)
+
+	m.prometheus.vmSkuRestriction = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_vmsku_restriction",
+			Help: "Azure ResourceManager VM SKU restriction",
+		},
+		[]string{
+			"subscriptionID",
+			"location",
+			"vmSize",
+			"type",
+			"reasonCode",
+			"zones",
+		},
+	)
+	m.Collector.RegisterMetricList("vmSkuRestriction", m.prometheus.vmSkuRestriction, true)
+
+	m.prometheus.vmSkuCapability = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_vmsku_capability",
+			Help: "Azure ResourceManager VM SKU capability (eg. vCPUs, MemoryGB)",
+		},
+		[]string{
+			"subscriptionID",
+			"location",
+			"vmSize",
+			"capability",
+		},
+	)
+	m.Collector.RegisterMetricList("vmSkuCapability", m.prometheus.vmSkuCapability, true)
+}
+
+func (m *MetricsCollectorAzureRmVmSku) Reset() {}
+
+func (m *MetricsCollectorAzureRmVmSku) Collect(callback chan<- func()) {
+	err := AzureSubscriptionsIterator.ForEachAsync(m.Logger(), func(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+		if registered, err := AzureClient.IsResourceProviderRegistered(m.Context(), *subscription.SubscriptionID, "Microsoft.Compute"); registered {
+			m.collectVmSkus(subscription, logger, callback)
+		} else if err != nil {
+			logger.Error(err.Error())
+		}
+	})
+	if err != nil {
+		m.Logger().Panic(err)
+	}
+}
+
+// collectVmSkus collects VM SKU availability, zones, restrictions and capabilities for configured locations
+func (m *MetricsCollectorAzureRmVmSku) collectVmSkus(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger, callback chan<- func()) {
+	client, err := armcompute.NewResourceSKUsClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	vmSkuInfoMetric := m.Collector.GetMetricList("vmSkuInfo")
+	vmSkuAvailableMetric := m.Collector.GetMetricList("vmSkuAvailable")
+	vmSkuZoneMetric := m.Collector.GetMetricList("vmSkuZone")
+	vmSkuRestrictionMetric := m.Collector.GetMetricList("vmSkuRestriction")
+	vmSkuCapabilityMetric := m.Collector.GetMetricList("vmSkuCapability")
+
+	vmSizeList := map[string]bool{}
+	for _, vmSize := range Config.Collectors.VmSku.VmSizes {
+		vmSizeList[strings.ToLower(vmSize)] = true
+	}
+
+	capabilityList := map[string]bool{}
+	for _, capability := range Config.Collectors.VmSku.GetCapabilities() {
+		capabilityList[strings.ToLower(capability)] = true
+	}
+
+	for _, location := range Config.Azure.Locations {
+		location = strings.ToLower(location)
+
+		pager := client.NewListPager(&armcompute.ResourceSKUsClientListOptions{
+			Filter: to.StringPtr(fmt.Sprintf(`location eq '%s'`, location)),
+		})
+
+		for pager.More() {
+			result, err := pager.NextPage(m.Context())
+			if err != nil {
+				logger.Panic(err)
+			}
+
+			for _, sku := range result.Value {
+				if !strings.EqualFold(to.String(sku.ResourceType), "virtualMachines") {
+					continue
+				}
+
+				if len(vmSizeList) > 0 && !vmSizeList[to.StringLower(sku.Name)] {
+					continue
+				}
+
+				// without configured VM sizes only restricted VM sizes are exported (hundreds of VM sizes per location)
+				if len(vmSizeList) == 0 && len(sku.Restrictions) == 0 {
+					continue
+				}
+
+				vmSize := to.String(sku.Name)
+
+				vmSkuInfoMetric.AddInfo(prometheus.Labels{
+					"subscriptionID": to.StringLower(subscription.SubscriptionID),
+					"location":       location,
+					"vmSize":         vmSize,
+					"family":         to.String(sku.Family),
+					"tier":           to.String(sku.Tier),
+					"size":           to.String(sku.Size),
+				})
+
+				// restrictions
+				locationRestricted := false
+				restrictedZones := map[string]bool{}
+				for _, restriction := range sku.Restrictions {
+					restrictionType := ""
+					if restriction.Type != nil {
+						restrictionType = string(*restriction.Type)
+					}
+
+					reasonCode := ""
+					if restriction.ReasonCode != nil {
+						reasonCode = string(*restriction.ReasonCode)
+					}
+
+					zones := []string{}
+					if restriction.RestrictionInfo != nil {
+						for _, zone := range restriction.RestrictionInfo.Zones {
+							zones = append(zones, to.String(zone))
+							restrictedZones[to.String(zone)] = true
+						}
+					}
+					sort.Strings(zones)
+
+					if restriction.Type != nil && *restriction.Type == armcompute.ResourceSKURestrictionsTypeLocation {
+						locationRestricted = true
+					}
+
+					vmSkuRestrictionMetric.AddInfo(prometheus.Labels{
+						"subscriptionID": to.StringLower(subscription.SubscriptionID),
+						"location":       location,
+						"vmSize":         vmSize,
+						"type":           restrictionType,
+						"reasonCode":     reasonCode,
+						"zones":          strings.Join(zones, ","),
+					})
+				}
+
+				vmSkuAvailableMetric.AddBool(prometheus.Labels{
+					"subscriptionID": to.StringLower(subscription.SubscriptionID),
+					"location":       location,
+					"vmSize":         vmSize,
+				}, !locationRestricted)
+
+				// zones
+				for _, locationInfo := range sku.LocationInfo {
+					if !strings.EqualFold(to.String(locationInfo.Location), location) {
+						continue
+					}
+
+					for _, zone := range locationInfo.Zones {
+						vmSkuZoneMetric.AddBool(prometheus.Labels{
+							"subscriptionID": to.StringLower(subscription.SubscriptionID),
+							"location":       location,
+							"vmSize":         vmSize,
+							"zone":           to.String(zone),
+						}, !locationRestricted && !restrictedZones[to.String(zone)])
+					}
+				}
+
+				// capabilities
+				for _, capability := range sku.Capabilities {
+					if !capabilityList[to.StringLower(capability.Name)] {
+						continue
+					}
+
+					if value, err := strconv.ParseFloat(to.String(capability.Value), 64); err == nil {
+						vmSkuCapabilityMetric.Add(prometheus.Labels{
+							"subscriptionID": to.StringLower(subscription.SubscriptionID),
+							"location":       location,
+							"vmSize":         vmSize,
+							"capability":     to.String(capability.Name),
+						}, value)
+					}
+				}
+			}
+		}
+	}
+}
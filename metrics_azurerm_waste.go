@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/azuresdk/armclient"
+	"github.com/webdevops/go-common/prometheus/collector"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
+
+	"github.com/webdevops/azure-resourcemanager-exporter/config"
+)
+
+const (
+	WasteAppServicePlanApiVersion = "2022-09-01"
+)
+
+type (
+	appServicePlanResource struct {
+		ID         string `json:"id"`
+		Location   string `json:"location"`
+		Properties struct {
+			NumberOfSites *int `json:"numberOfSites"`
+		} `json:"properties"`
+	}
+)
+
+type MetricsCollectorAzureRmWaste struct {
+	collector.Processor
+
+	prometheus struct {
+		wasteResource *prometheus.GaugeVec
+	}
+}
+
+func (m *MetricsCollectorAzureRmWaste) Setup(collector *collector.Collector) {
+	m.Processor.Setup(collector)
+
+	m.prometheus.wasteResource = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "azurerm_waste_resource",
+			Help: "Azure resources which are orphaned or idle (unattached, empty or old)",
+		},
+		AzureResourceTagManager.AddToPrometheusLabels(
+			[]string{
+				"reason",
+				"resourceID",
+				"resourceName",
+				"subscriptionID",
+				"resourceGroup",
+				"resourceType",
+				"location",
+			},
+		),
+	)
+	m.Collector.RegisterMetricList("wasteResource", m.prometheus.wasteResource, true)
+}
+
+func (m *MetricsCollectorAzureRmWaste) Reset() {}
+
+func (m *MetricsCollectorAzureRmWaste) Collect(callback chan<- func()) {
+	err := AzureSubscriptionsIterator.ForEachAsync(m.Logger(), func(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+		if Config.Collectors.Waste.IsReasonEnabled(config.WasteReasonUnattachedDisk) {
+			m.collectUnattachedDisks(subscription, logger)
+		}
+
+		if Config.Collectors.Waste.IsReasonEnabled(config.WasteReasonOldSnapshot) {
+			m.collectOldSnapshots(subscription, logger)
+		}
+
+		if Config.Collectors.Waste.IsReasonEnabled(config.WasteReasonUnattachedNetworkInterface) {
+			m.collectUnattachedNetworkInterfaces(subscription, logger)
+		}
+
+		if Config.Collectors.Waste.IsReasonEnabled(config.WasteReasonUnassociatedPublicIp) {
+			m.collectUnassociatedPublicIps(subscription, logger)
+		}
+
+		if Config.Collectors.Waste.IsReasonEnabled(config.WasteReasonEmptyLoadBalancer) {
+			m.collectEmptyLoadBalancers(subscription, logger)
+		}
+
+		if Config.Collectors.Waste.IsReasonEnabled(config.WasteReasonEmptyAppServicePlan) {
+			m.collectEmptyAppServicePlans(subscription, logger)
+		}
+
+		if Config.Collectors.Waste.IsReasonEnabled(config.WasteReasonEmptyResourceGroup) {
+			m.collectEmptyResourceGroups(subscription, logger)
+		}
+	})
+	if err != nil {
+		m.Logger().Panic(err)
+	}
+}
+
+// addWasteResource adds resource with reason and resource tags to azurerm_waste_resource
+func (m *MetricsCollectorAzureRmWaste) addWasteResource(reason string, resourceId string, location *string) {
+	azureResource, err := armclient.ParseResourceId(resourceId)
+	if err != nil {
+		m.Logger().Warnf(`unable to parse resourceID "%v": %v`, resourceId, err.Error())
+		return
+	}
+
+	labels := prometheus.Labels{
+		"reason":         reason,
+		"resourceID":     stringToStringLower(resourceId),
+		"resourceName":   azureResource.ResourceName,
+		"subscriptionID": azureResource.Subscription,
+		"resourceGroup":  azureResource.ResourceGroup,
+		"resourceType":   azureResource.ResourceType,
+		"location":       to.StringLower(location),
+	}
+	labels = AzureResourceTagManager.AddResourceTagsToPrometheusLabels(m.Context(), labels, resourceId)
+
+	m.Collector.GetMetricList("wasteResource").AddInfo(labels)
+}
+
+// collectUnattachedDisks reports managed disks which are not attached to any VM
+func (m *MetricsCollectorAzureRmWaste) collectUnattachedDisks(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	client, err := armcompute.NewDisksClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		result, err := pager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		for _, disk := range result.Value {
+			if disk.ManagedBy != nil || len(disk.ManagedByExtended) > 0 {
+				continue
+			}
+
+			if disk.Properties != nil && disk.Properties.DiskState != nil && *disk.Properties.DiskState != armcompute.DiskStateUnattached {
+				continue
+			}
+
+			m.addWasteResource(config.WasteReasonUnattachedDisk, to.String(disk.ID), disk.Location)
+		}
+	}
+}
+
+// collectOldSnapshots reports snapshots older than snapshotMaxAgeDays
+func (m *MetricsCollectorAzureRmWaste) collectOldSnapshots(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	client, err := armcompute.NewSnapshotsClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	maxCreationTime := time.Now().AddDate(0, 0, -Config.Collectors.Waste.GetSnapshotMaxAgeDays())
+
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		result, err := pager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		for _, snapshot := range result.Value {
+			if snapshot.Properties == nil || snapshot.Properties.TimeCreated == nil {
+				continue
+			}
+
+			if snapshot.Properties.TimeCreated.Before(maxCreationTime) {
+				m.addWasteResource(config.WasteReasonOldSnapshot, to.String(snapshot.ID), snapshot.Location)
+			}
+		}
+	}
+}
+
+// collectUnattachedNetworkInterfaces reports NICs which are not attached to a VM (ignoring private endpoints and private link services)
+func (m *MetricsCollectorAzureRmWaste) collectUnattachedNetworkInterfaces(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	client, err := armnetwork.NewInterfacesClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	pager := client.NewListAllPager(nil)
+	for pager.More() {
+		result, err := pager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		for _, networkInterface := range result.Value {
+			if networkInterface.Properties == nil {
+				continue
+			}
+
+			if networkInterface.Properties.VirtualMachine != nil || networkInterface.Properties.PrivateEndpoint != nil || networkInterface.Properties.PrivateLinkService != nil {
+				continue
+			}
+
+			m.addWasteResource(config.WasteReasonUnattachedNetworkInterface, to.String(networkInterface.ID), networkInterface.Location)
+		}
+	}
+}
+
+// collectUnassociatedPublicIps reports public IPs without ipConfiguration and NAT gateway
+func (m *MetricsCollectorAzureRmWaste) collectUnassociatedPublicIps(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	client, err := armnetwork.NewPublicIPAddressesClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	pager := client.NewListAllPager(nil)
+	for pager.More() {
+		result, err := pager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		for _, publicIp := range result.Value {
+			if publicIp.Properties == nil {
+				continue
+			}
+
+			if publicIp.Properties.IPConfiguration != nil || publicIp.Properties.NatGateway != nil {
+				continue
+			}
+
+			m.addWasteResource(config.WasteReasonUnassociatedPublicIp, to.String(publicIp.ID), publicIp.Location)
+		}
+	}
+}
+
+// collectEmptyLoadBalancers reports load balancers without any backend pool members
+func (m *MetricsCollectorAzureRmWaste) collectEmptyLoadBalancers(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	client, err := armnetwork.NewLoadBalancersClient(*subscription.SubscriptionID, AzureClient.GetCred(), AzureClient.NewArmClientOptions())
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	pager := client.NewListAllPager(nil)
+	for pager.More() {
+		result, err := pager.NextPage(m.Context())
+		if err != nil {
+			logger.Panic(err)
+		}
+
+		for _, loadBalancer := range result.Value {
+			if loadBalancer.Properties == nil {
+				continue
+			}
+
+			backendCount := 0
+			for _, backendPool := range loadBalancer.Properties.BackendAddressPools {
+				if backendPool.Properties != nil {
+					backendCount += len(backendPool.Properties.BackendIPConfigurations)
+					backendCount += len(backendPool.Properties.LoadBalancerBackendAddresses)
+				}
+			}
+
+			if backendCount == 0 {
+				m.addWasteResource(config.WasteReasonEmptyLoadBalancer, to.String(loadBalancer.ID), loadBalancer.Location)
+			}
+		}
+	}
+}
+
+// collectEmptyAppServicePlans reports App Service plans without any apps
+func (m *MetricsCollectorAzureRmWaste) collectEmptyAppServicePlans(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	urlPath := "/subscriptions/" + *subscription.SubscriptionID + "/providers/Microsoft.Web/serverfarms"
+	appServicePlans, err := listArmApiResources(m.Context(), urlPath, url.Values{"api-version": {WasteAppServicePlanApiVersion}})
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	for _, row := range appServicePlans {
+		appServicePlan := appServicePlanResource{}
+		if err := json.Unmarshal(row, &appServicePlan); err != nil {
+			logger.Panic(err)
+		}
+
+		if appServicePlan.Properties.NumberOfSites != nil && *appServicePlan.Properties.NumberOfSites == 0 {
+			m.addWasteResource(config.WasteReasonEmptyAppServicePlan, appServicePlan.ID, to.StringPtr(appServicePlan.Location))
+		}
+	}
+}
+
+// collectEmptyResourceGroups reports resource groups without any resources
+func (m *MetricsCollectorAzureRmWaste) collectEmptyResourceGroups(subscription *armsubscriptions.Subscription, logger *zap.SugaredLogger) {
+	resourceGroupList, err := AzureClient.ListResourceGroups(m.Context(), *subscription.SubscriptionID)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	resourceList, err := AzureClient.ListCachedResources(m.Context(), *subscription.SubscriptionID)
+	if err != nil {
+		logger.Panic(err)
+	}
+
+	usedResourceGroups := map[string]bool{}
+	for resourceId := range resourceList {
+		if azureResource, err := armclient.ParseResourceId(resourceId); err == nil {
+			usedResourceGroups[azureResource.ResourceGroup] = true
+		}
+	}
+
+	for resourceGroupName, resourceGroup := range resourceGroupList {
+		if _, exists := usedResourceGroups[strings.ToLower(resourceGroupName)]; exists {
+			continue
+		}
+
+		m.addWasteResource(config.WasteReasonEmptyResourceGroup, to.String(resourceGroup.ID), resourceGroup.Location)
+	}
+}
@@ -1,9 +1,24 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode/utf8"
+
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/webdevops/go-common/prometheus/collector"
+	"github.com/webdevops/go-common/utils/to"
+	"go.uber.org/zap"
 )
 
 var (
@@ -39,3 +54,121 @@ func truncateStrings(s string, n int, suffix string) string {
 	}
 	return s[:n] + suffix
 }
+
+// prometheusLabelsKey builds an unique key for the label set
+func prometheusLabelsKey(labels prometheus.Labels) string {
+	labelNames := make([]string, 0, len(labels))
+	for labelName := range labels {
+		labelNames = append(labelNames, labelName)
+	}
+	sort.Strings(labelNames)
+
+	key := strings.Builder{}
+	for _, labelName := range labelNames {
+		key.WriteString(labelName)
+		key.WriteString("=")
+		key.WriteString(labels[labelName])
+		key.WriteString("\x00")
+	}
+
+	return key.String()
+}
+
+// restoreCollectorData restores custom collector data (SetData) from cache file after restart
+// collector data is not restored by go-common, only file caches are supported (not azblob and k8scm)
+// cacheTagValues must match the cache tag of the collector (see initMetricCollector), data of other tags is rejected
+// cache expiry is ignored as the data is meant to outlive the cached metrics
+func restoreCollectorData(c *collector.Collector, logger *zap.SugaredLogger, dataName string, target interface{}, cacheTagValues ...interface{}) bool {
+	cachePath := Opts.GetCachePath(c.Name + ".json")
+	if cachePath == nil {
+		return false
+	}
+
+	filePath := strings.TrimPrefix(*cachePath, "file://")
+	if strings.Contains(filePath, "://") {
+		logger.Debugf(`unable to restore "%v" from cache "%v", only file caches are supported`, dataName, *cachePath)
+		return false
+	}
+
+	content, err := os.ReadFile(filePath) // #nosec inside container
+	if err != nil {
+		return false
+	}
+
+	cacheData := struct {
+		Data map[string]json.RawMessage `json:"data"`
+		Tag  *string                    `json:"tag"`
+	}{}
+	if err := json.Unmarshal(content, &cacheData); err != nil {
+		logger.Warnf(`unable to restore "%v" from cache "%v": %v`, dataName, *cachePath, err.Error())
+		return false
+	}
+
+	expectedTag := collector.BuildCacheTag(cacheTag, cacheTagValues...)
+	if cacheData.Tag == nil || to.String(cacheData.Tag) != to.String(expectedTag) {
+		logger.Infof(`ignoring "%v" from cache "%v", cache tag mismatch (config changed)`, dataName, *cachePath)
+		return false
+	}
+
+	data, exists := cacheData.Data[dataName]
+	if !exists || string(data) == "null" {
+		return false
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		logger.Warnf(`unable to restore "%v" from cache "%v": %v`, dataName, *cachePath, err.Error())
+		return false
+	}
+
+	return true
+}
+
+// listArmApiResources lists resources (with paging) from ResourceManager REST APIs not covered by the SDK clients in use
+// every failure (including unexpected status codes) is returned as error, partial results are not returned
+func listArmApiResources(ctx context.Context, urlPath string, query url.Values) ([]json.RawMessage, error) {
+	options := AzureClient.NewArmClientOptions()
+	ep := cloud.AzurePublic.Services[cloud.ResourceManager].Endpoint
+	if c, ok := options.Cloud.Services[cloud.ResourceManager]; ok {
+		ep = c.Endpoint
+	}
+
+	pl, err := armruntime.NewPipeline("azurerm-exporter", gitTag, AzureClient.GetCred(), runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []json.RawMessage{}
+	nextLink := runtime.JoinPaths(ep, urlPath) + "?" + query.Encode()
+	for nextLink != "" {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, nextLink)
+		if err != nil {
+			return nil, err
+		}
+		req.Raw().Header["Accept"] = []string{"application/json"}
+
+		resp, err := pl.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if !runtime.HasStatusCode(resp, http.StatusOK) {
+			resp.Body.Close() // nolint:errcheck
+			return nil, fmt.Errorf(`unable to list "%v": unexpected status code %v`, urlPath, resp.StatusCode)
+		}
+
+		result := struct {
+			Value    []json.RawMessage `json:"value"`
+			NextLink *string           `json:"nextLink"`
+		}{}
+		err = runtime.UnmarshalAsJSON(resp, &result)
+		resp.Body.Close() // nolint:errcheck
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, result.Value...)
+		nextLink = to.String(result.NextLink)
+	}
+
+	return list, nil
+}